@@ -2,37 +2,143 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
+	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"fristGoproject/internal/audit"
 	"fristGoproject/internal/auth"
+	"fristGoproject/internal/avatar"
 	"fristGoproject/internal/db"
+	"fristGoproject/internal/db/migrate"
+	"fristGoproject/internal/db/sqlite"
+	"fristGoproject/internal/event"
 	"fristGoproject/internal/httpapi"
+	"fristGoproject/internal/mail"
+	"fristGoproject/internal/org"
+	"fristGoproject/internal/storage"
 	"fristGoproject/internal/user"
+	"fristGoproject/internal/webhook"
+	"fristGoproject/pkg/jsonschema"
+	"fristGoproject/pkg/password"
 )
 
 func main() {
+	autoMigrate, _ := strconv.ParseBool(os.Getenv("AUTO_MIGRATE"))
+	flag.BoolVar(&autoMigrate, "migrate", autoMigrate, "รัน database migration ก่อนเริ่มเซิร์ฟเวอร์ (หรือตั้ง AUTO_MIGRATE=true)")
+	flag.Parse()
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	pool, err := db.Connect(ctx)
+	dbCfg, err := db.LoadConfig()
 	if err != nil {
-		log.Fatalf("unable to connect database: %v", err)
+		log.Fatalf("invalid database config: %v", err)
+	}
+
+	blobs, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatalf("unable to configure blob storage: %v", err)
+	}
+
+	mailer, err := mail.NewFromEnv()
+	if err != nil {
+		log.Fatalf("unable to configure mail: %v", err)
+	}
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	eventCfg, err := event.LoadConfig()
+	if err != nil {
+		log.Fatalf("invalid event config: %v", err)
 	}
-	defer pool.Close()
+	webhookCfg, err := webhook.LoadConfig()
+	if err != nil {
+		log.Fatalf("invalid webhook config: %v", err)
+	}
+	hashParams, err := password.LoadParams()
+	if err != nil {
+		log.Fatalf("invalid password hash config: %v", err)
+	}
+	password.SetParams(hashParams)
+	peppers, err := password.LoadPeppers()
+	if err == nil {
+		err = password.SetPeppers(peppers)
+	}
+	if err != nil {
+		log.Fatalf("invalid password pepper: %v", err)
+	}
+	if len(peppers) > 0 {
+		log.Printf("password pepper: hashing with %q, %d older key(s) for verification", peppers[0].ID, len(peppers)-1)
+	}
+	hashLimits, err := password.LoadLimits()
+	if err != nil {
+		log.Fatalf("invalid password hash config: %v", err)
+	}
+	password.SetLimits(hashLimits)
+	log.Printf("password hashing: argon2id m=%d KiB t=%d p=%d, %d concurrent, queue %d, max wait %s",
+		hashParams.Memory, hashParams.Time, hashParams.Threads, hashLimits.Concurrency, hashLimits.Queue, hashLimits.MaxWait)
+
+	st := openStores(ctx, dbCfg, autoMigrate)
+	defer st.close()
+	userRepo, txm := st.users, st.tx
+
+	webhookSvc := webhook.NewService(st.webhooks)
+	sinks := append(eventCfg.Sinks, webhookSvc.Sink())
+	go event.NewDispatcher(st.outbox, sinks, eventCfg).Run(ctx)
+	go webhook.NewWorker(st.webhooks, webhookCfg).Run(ctx)
 
-	userRepo := user.NewRepository(pool)
-	authSvc := auth.NewService(userRepo)
+	auditSvc := audit.NewService(st.audit)
+	authSvc := auth.NewService(userRepo, txm, st.outbox, auditSvc)
 	authHandler := httpapi.NewAuthHandler(authSvc)
-	userSvc := user.NewService(userRepo)
-	userHandler := httpapi.NewUserHandler(userSvc)
+	avatarSvc := avatar.NewService(blobs, userRepo)
+	avatarHandler := httpapi.NewAvatarHandler(avatarSvc)
+	orgSvc := org.NewService(st.orgs, userRepo, txm, mailer, auditSvc, baseURL+httpapi.APIPrefix+httpapi.InvitationAcceptPath)
+	orgHandler := httpapi.NewOrgHandler(orgSvc)
+	userSvc := user.NewService(userRepo, txm, st.outbox, avatarSvc, orgSvc, auditSvc, event.NewDataSource(st.outbox), webhookSvc)
+	if path := os.Getenv("PROFILE_SCHEMA_FILE"); path != "" {
+		schema, err := loadProfileSchema(path)
+		if err != nil {
+			log.Fatalf("unable to load profile schema: %v", err)
+		}
+		userSvc.SetAttributeValidator(schema)
+	}
+	if normalize, _ := strconv.ParseBool(os.Getenv("PASSWORD_HASH_NORMALIZE")); normalize {
+		go func() {
+			n, err := userSvc.NormalizePasswordHashes(ctx, 100*time.Millisecond)
+			if err != nil {
+				log.Printf("normalize password hashes: %v (converted %d)", err, n)
+				return
+			}
+			log.Printf("normalize password hashes: converted %d", n)
+		}()
+	}
+	userHandler := httpapi.NewUserHandler(userSvc, orgSvc)
+	authn := httpapi.NewAuthenticator(authSvc, os.Getenv("ADMIN_API_KEY"), auditSvc)
+	adminHandler := httpapi.NewAdminHandler(userSvc)
+	tenancy := httpapi.NewTenancy(orgSvc)
 
 	router := httpapi.NewRouter()
 	router.RegisterAuthRoutes(authHandler)
-	router.RegisterUserRoutes(userHandler)
+	router.RegisterUserRoutes(userHandler, authn, tenancy)
+	router.RegisterOrgRoutes(orgHandler, authn, tenancy)
+	router.RegisterAvatarRoutes(avatarHandler, authn)
+	router.RegisterAdminRoutes(adminHandler, authn)
+	router.RegisterWebhookRoutes(httpapi.NewWebhookHandler(webhookSvc), authn)
+	router.RegisterAuditRoutes(httpapi.NewAuditHandler(auditSvc), authn)
+	if local, ok := blobs.(*storage.LocalStore); ok {
+		router.ServeMedia(local.Dir())
+	}
 	router.ServeDocs("docs")
 
 	server := &http.Server{
@@ -45,11 +151,19 @@ func main() {
 
 	log.Println("server running at http://localhost:8080")
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	// ListenAndServe บล็อกจนเซิร์ฟเวอร์ปิด จึงต้องรันแยก แล้วรอสัญญาณ SIGINT/SIGTERM ตรงนี้
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
 		log.Fatalf("server error: %v", err)
+	case <-ctx.Done():
 	}
-
-	<-ctx.Done()
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer shutdownCancel()
 	if err := server.Shutdown(shutdownCtx); err != nil {
@@ -58,3 +172,88 @@ func main() {
 
 	log.Println("server stopped")
 }
+
+// loadProfileSchema อ่าน JSON Schema ของ custom profile attributes ที่ admin กำหนดไว้ในไฟล์
+func loadProfileSchema(path string) (*jsonschema.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.Compile(data)
+}
+
+// stores คือที่เก็บข้อมูลทั้งหมดของเซิร์ฟเวอร์ที่เปิดตาม DB_DRIVER
+type stores struct {
+	users    user.Repository
+	orgs     org.Repository
+	outbox   event.Store
+	webhooks webhook.Repository
+	audit    audit.Store
+	tx       db.Transactor
+	close    func()
+}
+
+// openStores เปิดที่เก็บข้อมูลตาม DB_DRIVER ทุก store ใช้ฐานเดียวกันเพื่อให้ event อยู่ใน transaction เดียวกับข้อมูล
+func openStores(ctx context.Context, cfg db.Config, autoMigrate bool) stores {
+	if cfg.Driver == db.DriverMemory {
+		log.Println("DB_DRIVER=memory: data is kept in memory and lost on restart")
+		users := user.NewMemoryRepository()
+		orgs := org.NewMemoryRepository(users)
+		users.SetMemberLookup(orgs.MemberIDs)
+		return stores{
+			users:    users,
+			orgs:     orgs,
+			outbox:   event.NewMemoryStore(),
+			webhooks: webhook.NewMemoryRepository(),
+			audit:    audit.NewMemoryStore(),
+			tx:       db.NoTx{},
+			close:    func() {},
+		}
+	}
+	if cfg.Driver == db.DriverSQLite {
+		// schema ของ SQLite ฝังมากับ binary และถูก migrate ทุกครั้งที่เปิด ไม่ขึ้นกับ AUTO_MIGRATE
+		sqldb, err := sqlite.Open(ctx, cfg.SQLitePath)
+		if err != nil {
+			log.Fatalf("unable to open sqlite database: %v", err)
+		}
+		log.Printf("DB_DRIVER=sqlite: using %s", cfg.SQLitePath)
+		return stores{
+			users:    user.NewSQLiteRepository(sqldb),
+			orgs:     org.NewSQLiteRepository(sqldb),
+			outbox:   event.NewSQLiteStore(sqldb),
+			webhooks: webhook.NewSQLiteRepository(sqldb),
+			audit:    audit.NewSQLiteStore(sqldb),
+			tx:       sqlite.NewTxManager(sqldb),
+			close:    func() { sqldb.Close() },
+		}
+	}
+
+	cluster, err := db.Connect(ctx, cfg)
+	if err != nil {
+		log.Fatalf("unable to connect database: %v", err)
+	}
+
+	if autoMigrate {
+		migrator, err := migrate.New(cluster.Primary)
+		if err != nil {
+			log.Fatalf("unable to load migrations: %v", err)
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("migration failed: %v", err)
+		}
+		for _, m := range applied {
+			log.Printf("migrated %04d_%s", m.Version, m.Name)
+		}
+	}
+
+	return stores{
+		users:    user.NewRepository(cluster),
+		orgs:     org.NewRepository(cluster.Primary),
+		outbox:   event.NewStore(cluster.Primary),
+		webhooks: webhook.NewRepository(cluster.Primary),
+		audit:    audit.NewStore(cluster.Primary),
+		tx:       db.NewTxManager(cluster.Primary),
+		close:    cluster.Close,
+	}
+}
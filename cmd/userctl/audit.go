@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"fristGoproject/internal/audit"
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/db/sqlite"
+)
+
+// errAuditTampered ทำให้ userctl จบด้วย exit code 1 เมื่อสายของ audit log ถูกแก้ไข (ใช้ใน cron/CI ได้)
+var errAuditTampered = errors.New("audit log ถูกแก้ไข")
+
+func runAudit(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "พิมพ์ผลเป็น JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: userctl audit [-json] verify")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || fs.Arg(0) != "verify" {
+		fs.Usage()
+		return errors.New("ต้องระบุ verify")
+	}
+
+	store, closeDB, err := openAuditStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	report, err := audit.NewService(store).Verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range report.Problems {
+			fmt.Printf("id %d: %s\n", p.ID, p.Reason)
+		}
+		fmt.Printf("ตรวจแล้ว %d รายการ, ล่าสุด id %d hash %s\n", report.Checked, report.LastID, report.LastHash)
+	}
+	if !report.OK() {
+		return fmt.Errorf("%w: พบ %d จุด", errAuditTampered, len(report.Problems))
+	}
+	return nil
+}
+
+func openAuditStore(ctx context.Context) (audit.Store, func(), error) {
+	cfg, err := db.LoadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Driver == db.DriverSQLite {
+		sqldb, err := sqlite.Open(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open sqlite: %w", err)
+		}
+		return audit.NewSQLiteStore(sqldb), func() { sqldb.Close() }, nil
+	}
+
+	cluster, err := connectDB(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return audit.NewStore(cluster.Primary), cluster.Close, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fristGoproject/internal/user"
+)
+
+func runImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "ไฟล์ที่จะนำเข้า (- คือ stdin)")
+	format := fs.String("format", "", "csv หรือ jsonl (ถ้าไม่ระบุจะดูจากนามสกุลไฟล์)")
+	dryRun := fs.Bool("dry-run", false, "ตรวจอย่างเดียว ไม่เขียนลงฐานข้อมูล")
+	onDuplicate := fs.String("on-duplicate", "skip", "skip, update หรือ fail เมื่ออีเมลซ้ำ")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("ต้องระบุ -file")
+	}
+
+	policy, err := user.ParseDuplicatePolicy(*onDuplicate)
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	src, err := user.NewRowReader(*format, bufio.NewReader(in))
+	if err != nil {
+		return err
+	}
+
+	svc, closeDB, err := newUserService(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	result, err := svc.Import(ctx, src, user.ImportOptions{DryRun: *dryRun, OnDuplicate: policy})
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(result)
+	if err != nil {
+		return err
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%d แถวนำเข้าไม่สำเร็จ", result.Failed)
+	}
+	return nil
+}
+
+func runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("out", "-", "ไฟล์ปลายทาง (- คือ stdout)")
+	format := fs.String("format", "jsonl", "csv หรือ jsonl")
+	includeHash := fs.Bool("include-password-hash", false, "ส่งออก password_hash ด้วย (ใช้ตอนย้ายระบบเท่านั้น)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	svc, closeDB, err := newUserService(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	buf := bufio.NewWriter(w)
+
+	dst, err := user.NewRowWriter(*format, buf, *includeHash)
+	if err != nil {
+		return err
+	}
+	if err := svc.ExportAll(ctx, dst); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"runtime"
+	"time"
+
+	"fristGoproject/pkg/password"
+)
+
+func runCalibrate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("calibrate", flag.ContinueOnError)
+	target := fs.Duration("target", 250*time.Millisecond, "เวลาต่อ hash ที่ต้องการ")
+	memory := fs.Uint("memory", uint(password.DefaultParams.Memory), "หน่วยความจำต่อ hash เป็น KiB (จะถูกลดถ้า time=1 ยังช้าเกิน)")
+	threads := fs.Uint("threads", uint(min(runtime.GOMAXPROCS(0), 4)), "จำนวน thread ต่อ hash")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: userctl calibrate [-target 250ms] [-memory KiB] [-threads n]")
+		fmt.Fprintln(fs.Output(), "วัดความเร็ว Argon2id บนเครื่องนี้แล้วพิมพ์ env ที่ใช้ตั้งเซิร์ฟเวอร์ (รันบนเครื่องเดียวกับ production)")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *memory > 1<<32-1 || *threads < 1 || *threads > 255 {
+		return errors.New("memory ต้องไม่เกิน 4294967295 KiB และ threads ต้องอยู่ระหว่าง 1 ถึง 255")
+	}
+
+	p, took, err := password.Calibrate(*target, uint32(*memory), uint8(*threads))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("ARGON2_TIME=%d\n", p.Time)
+	fmt.Printf("ARGON2_MEMORY_KIB=%d\n", p.Memory)
+	fmt.Printf("ARGON2_THREADS=%d\n", p.Threads)
+	fmt.Printf("# %s ต่อ hash (เป้าหมาย %s)\n", took.Round(time.Millisecond), *target)
+	if took > *target {
+		fmt.Println("# ช้ากว่าเป้าหมายแม้ใช้ค่าต่ำสุดแล้ว ลองเพิ่ม CPU หรือลด -threads")
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Command userctl รวมคำสั่งสำหรับผู้ดูแลระบบที่ต้องรันกับฐานข้อมูลโดยตรง
+// เช่นนำเข้า/ส่งออกผู้ใช้จำนวนมากตอนย้ายระบบ
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/db/sqlite"
+	"fristGoproject/internal/event"
+	"fristGoproject/internal/user"
+	"fristGoproject/pkg/jsonschema"
+	"fristGoproject/pkg/password"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"import", "นำเข้าผู้ใช้จากไฟล์ CSV หรือ JSON Lines", runImport},
+	{"export", "ส่งออกผู้ใช้ทั้งหมดเป็น CSV หรือ JSON Lines", runExport},
+	{"migrate", "จัดการ schema ของฐานข้อมูล (up, down, status)", runMigrate},
+	{"audit", "ตรวจว่า audit log ไม่ถูกแก้ไข (verify)", runAudit},
+	{"calibrate", "หาค่า Argon2 ที่ได้เวลาต่อ hash ตามเป้าหมายบนเครื่องนี้", runCalibrate},
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(ctx, os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "userctl %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: userctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "ใช้ userctl <command> -h เพื่อดู flag ของแต่ละคำสั่ง")
+}
+
+// connectDB เปิด Postgres ตาม env คำสั่งของ userctl ทำงานกับข้อมูลถาวรจึงไม่รองรับ DB_DRIVER=memory
+// (SQLite migrate ตัวเองตอนเปิด จึงไม่ต้องใช้คำสั่ง migrate)
+func connectDB(ctx context.Context) (*db.Cluster, error) {
+	cfg, err := db.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Driver != db.DriverPostgres {
+		return nil, fmt.Errorf("userctl ใช้ได้กับ DB_DRIVER=postgres เท่านั้น (ได้ %s)", cfg.Driver)
+	}
+	cluster, err := db.Connect(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+	return cluster, nil
+}
+
+// newUserService เปิดฐานข้อมูลและคืน user service พร้อม schema ของ attributes (ถ้าตั้งไว้)
+// รองรับทั้ง DB_DRIVER=postgres และ sqlite event ที่เกิดจากคำสั่ง (เช่น import) จะรอให้เซิร์ฟเวอร์ส่งต่อจาก outbox
+func newUserService(ctx context.Context) (*user.Service, func(), error) {
+	// รหัสผ่านที่นำเข้าต้อง hash ด้วยความเข้มและ pepper เดียวกับเซิร์ฟเวอร์
+	params, err := password.LoadParams()
+	if err != nil {
+		return nil, nil, err
+	}
+	password.SetParams(params)
+	peppers, err := password.LoadPeppers()
+	if err == nil {
+		err = password.SetPeppers(peppers)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svc, closeDB, err := openUserService(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if path := os.Getenv("PROFILE_SCHEMA_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			closeDB()
+			return nil, nil, fmt.Errorf("read profile schema: %w", err)
+		}
+		schema, err := jsonschema.Compile(data)
+		if err != nil {
+			closeDB()
+			return nil, nil, fmt.Errorf("compile profile schema: %w", err)
+		}
+		svc.SetAttributeValidator(schema)
+	}
+	return svc, closeDB, nil
+}
+
+func openUserService(ctx context.Context) (*user.Service, func(), error) {
+	cfg, err := db.LoadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Driver == db.DriverSQLite {
+		sqldb, err := sqlite.Open(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open sqlite: %w", err)
+		}
+		svc := user.NewService(user.NewSQLiteRepository(sqldb), sqlite.NewTxManager(sqldb), event.NewSQLiteStore(sqldb))
+		return svc, func() { sqldb.Close() }, nil
+	}
+
+	cluster, err := connectDB(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	svc := user.NewService(user.NewRepository(cluster), db.NewTxManager(cluster.Primary), event.NewStore(cluster.Primary))
+	return svc, cluster.Close, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"fristGoproject/internal/db/migrate"
+)
+
+func runMigrate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "จำนวนเวอร์ชันที่จะย้อนกลับ (ใช้กับ down)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: userctl migrate [-steps N] up|down|status")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("ต้องระบุ up, down หรือ status")
+	}
+
+	cluster, err := connectDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer cluster.Close()
+
+	migrator, err := migrate.New(cluster.Primary)
+	if err != nil {
+		return err
+	}
+
+	switch fs.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("up   %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema เป็นปัจจุบันแล้ว")
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, m := range reverted {
+			fmt.Printf("down %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+		drift := false
+		for _, st := range statuses {
+			applied, note := "-", ""
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			if st.Drift {
+				note = "checksum ไม่ตรงกับไฟล์"
+				drift = true
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", st.Version, st.Name, applied, note)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if drift {
+			return migrate.ErrChecksumMismatch
+		}
+		return nil
+	default:
+		fs.Usage()
+		return fmt.Errorf("ไม่รู้จักคำสั่ง %q", fs.Arg(0))
+	}
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// hashedFields คือ field ที่ถูกนำมาคำนวณ Hash ของรายการ ลำดับ field คงที่เพื่อให้ encode ได้ผลเดิมเสมอ
+// ข้อมูลส่วนบุคคลไม่อยู่ในนี้โดยตรงแต่ผ่าน PIIHash การล้างข้อมูลส่วนบุคคลจึงไม่ทำให้สายขาด
+type hashedFields struct {
+	PrevHash   string    `json:"prev_hash"`
+	OccurredAt string    `json:"occurred_at"`
+	Action     Action    `json:"action"`
+	ActorType  ActorType `json:"actor_type"`
+	ActorID    *int      `json:"actor_id"`
+	Target     string    `json:"target"`
+	RequestID  string    `json:"request_id"`
+	PIIHash    string    `json:"pii_hash"`
+}
+
+// piiFields คือข้อมูลส่วนบุคคลของรายการ salt แบบสุ่มกันการเดาค่า IP จาก PIIHash หลังข้อมูลถูกล้าง
+type piiFields struct {
+	Salt      string         `json:"salt"`
+	IP        string         `json:"ip"`
+	UserAgent string         `json:"user_agent"`
+	Details   map[string]any `json:"details"`
+}
+
+// seal เติม salt, PIIHash, PrevHash และ Hash ให้รายการที่จะต่อท้ายรายการที่มี hash เป็น prev
+func seal(e *Entry, prev string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	// ปัดเวลาและผ่าน details ไป-กลับ JSON ก่อน ให้ค่าที่คำนวณ hash ตรงกับค่าที่อ่านกลับจากฐาน
+	e.OccurredAt = e.OccurredAt.UTC().Truncate(time.Microsecond)
+	data, err := encodeDetails(e.Details)
+	if err != nil {
+		return err
+	}
+	if e.Details, err = decodeDetails(data); err != nil {
+		return err
+	}
+	e.PIISalt = hex.EncodeToString(salt)
+	piiHash, err := e.piiHash()
+	if err != nil {
+		return err
+	}
+	e.PIIHash = piiHash
+	e.PrevHash = prev
+	e.Hash, err = e.computeHash()
+	return err
+}
+
+func (e Entry) piiHash() (string, error) {
+	return sum(piiFields{Salt: e.PIISalt, IP: e.IP, UserAgent: e.UserAgent, Details: e.Details})
+}
+
+func (e Entry) computeHash() (string, error) {
+	return sum(hashedFields{
+		PrevHash:   e.PrevHash,
+		OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		Action:     e.Action,
+		ActorType:  e.ActorType,
+		ActorID:    e.ActorID,
+		Target:     e.Target,
+		RequestID:  e.RequestID,
+		PIIHash:    e.PIIHash,
+	})
+}
+
+// sum คืน SHA-256 hex ของ JSON (json.Marshal เรียง key ของ map ให้ จึงได้ผลเดิมทุกครั้ง)
+func sum(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// Problem คือรายการที่ตรวจไม่ผ่าน
+type Problem struct {
+	ID     int64  `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// Report คือผลการตรวจสายของ audit log
+// LastHash ควรจดเก็บไว้นอกระบบเป็นระยะ เพราะการตัดรายการท้ายสายทิ้งตรวจไม่ได้จากตัวสายเอง
+type Report struct {
+	Checked  int       `json:"checked"`
+	LastID   int64     `json:"last_id"`
+	LastHash string    `json:"last_hash"`
+	Problems []Problem `json:"problems"`
+}
+
+// OK บอกว่าสายไม่ถูกแก้ไข
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// check ตรวจรายการถัดไปในสาย (เรียงตาม id) แล้วเลื่อนจุดอ้างอิงไปที่รายการนั้น
+// ใช้ hash ที่เก็บไว้ของรายการเป็นจุดอ้างอิงต่อ รายการที่ถูกแก้หนึ่งรายการจึงถูกรายงานครั้งเดียว
+func (r *Report) check(e Entry) {
+	if e.PrevHash != r.LastHash {
+		r.Problems = append(r.Problems, Problem{ID: e.ID, Reason: "prev_hash ไม่ตรงกับ hash ของรายการก่อนหน้า (มีรายการถูกลบหรือแทรก)"})
+	}
+	if e.ErasedAt == nil {
+		if h, err := e.piiHash(); err != nil || h != e.PIIHash {
+			r.Problems = append(r.Problems, Problem{ID: e.ID, Reason: "ข้อมูล ip/user_agent/details ถูกแก้ไข"})
+		}
+	}
+	if h, err := e.computeHash(); err != nil || h != e.Hash {
+		r.Problems = append(r.Problems, Problem{ID: e.ID, Reason: "hash ไม่ตรงกับเนื้อหาของรายการ"})
+	}
+	r.Checked++
+	r.LastID = e.ID
+	r.LastHash = e.Hash
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// MemoryStore เก็บ audit log ไว้ในหน่วยความจำ ใช้คู่กับ DB_DRIVER=memory
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewMemoryStore คืน store ว่าง
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (m *MemoryStore) Append(ctx context.Context, e Entry) (Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var prev string
+	if n := len(m.entries); n > 0 {
+		prev = m.entries[n-1].Hash
+	}
+	if err := seal(&e, prev); err != nil {
+		return Entry{}, err
+	}
+	e.ID = int64(len(m.entries) + 1)
+	m.entries = append(m.entries, e)
+	return copyEntry(e), nil
+}
+
+func (m *MemoryStore) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := []Entry{}
+	for i := len(m.entries) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		if e := m.entries[i]; filter.match(e) {
+			entries = append(entries, copyEntry(e))
+		}
+	}
+	return entries, nil
+}
+
+func (m *MemoryStore) Scan(ctx context.Context, afterID int64, limit int) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := []Entry{}
+	for _, e := range m.entries {
+		if e.ID > afterID && len(entries) < limit {
+			entries = append(entries, copyEntry(e))
+		}
+	}
+	return entries, nil
+}
+
+func (m *MemoryStore) Erase(ctx context.Context, userID int, at time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for i, e := range m.entries {
+		if e.ErasedAt != nil || !(Filter{UserID: userID}).match(e) {
+			continue
+		}
+		at := at.UTC().Truncate(time.Microsecond)
+		e.IP, e.UserAgent, e.Details, e.PIISalt, e.ErasedAt = "", "", nil, "", &at
+		m.entries[i] = e
+		n++
+	}
+	return n, nil
+}
+
+// match ใช้เงื่อนไขเดียวกับ filterClause
+func (f Filter) match(e Entry) bool {
+	actorIs := func(id int) bool { return e.ActorID != nil && *e.ActorID == id }
+	switch {
+	case f.Action != "" && e.Action != f.Action,
+		f.ActorID > 0 && !actorIs(f.ActorID),
+		f.Target != "" && e.Target != f.Target,
+		f.UserID > 0 && !actorIs(f.UserID) && e.Target != UserTarget(f.UserID),
+		f.RequestID != "" && e.RequestID != f.RequestID,
+		!f.Since.IsZero() && e.OccurredAt.Before(f.Since),
+		!f.Until.IsZero() && !e.OccurredAt.Before(f.Until),
+		f.BeforeID > 0 && e.ID >= f.BeforeID:
+		return false
+	}
+	return true
+}
+
+func copyEntry(e Entry) Entry {
+	e.Details = maps.Clone(e.Details)
+	return e
+}
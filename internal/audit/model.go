@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Action คือชนิดของเหตุการณ์ด้านความปลอดภัยที่บันทึก
+type Action string
+
+const (
+	UserRegistered       Action = "user.registered"
+	LoginSucceeded       Action = "auth.login_succeeded"
+	LoginFailed          Action = "auth.login_failed"
+	PasswordChanged      Action = "auth.password_changed"
+	PasswordChangeFailed Action = "auth.password_change_failed"
+	RoleChanged          Action = "org.role_changed"
+	AdminRequest         Action = "admin.request"
+	AdminAuthFailed      Action = "admin.auth_failed"
+)
+
+// ActorType บอกว่าใครเป็นผู้กระทำ
+type ActorType string
+
+const (
+	// ActorAnonymous คือผู้เรียกที่ยังไม่ได้ยืนยันตัวตน เช่นคนที่ล็อกอินไม่ผ่าน
+	ActorAnonymous ActorType = "anonymous"
+	ActorUser      ActorType = "user"
+	// ActorAdmin คือผู้ถือ admin API key (key เดียวจึงไม่มี ID)
+	ActorAdmin ActorType = "admin"
+)
+
+// Actor คือผู้กระทำของเหตุการณ์ ID ใช้กับ ActorUser เท่านั้น
+type Actor struct {
+	Type ActorType
+	ID   int
+}
+
+// Entry คือเหตุการณ์หนึ่งรายการใน audit log
+// Hash คำนวณจาก PrevHash และ field หลักของรายการ (ดู chain.go) รายการจึงต่อกันเป็นสายที่แก้ไขแล้วตรวจพบได้
+// IP, UserAgent และ Details เป็นข้อมูลส่วนบุคคล ถูกล้างได้ตอนลบบัญชีโดยสายยังตรวจได้ผ่าน PIIHash
+type Entry struct {
+	ID         int64          `json:"id"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Action     Action         `json:"action"`
+	ActorType  ActorType      `json:"actor_type"`
+	ActorID    *int           `json:"actor_id,omitempty"`
+	Target     string         `json:"target,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	IP         string         `json:"ip,omitempty"`
+	UserAgent  string         `json:"user_agent,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	PIISalt    string         `json:"-"`
+	PIIHash    string         `json:"pii_hash"`
+	PrevHash   string         `json:"prev_hash"`
+	Hash       string         `json:"hash"`
+	ErasedAt   *time.Time     `json:"erased_at,omitempty"`
+}
+
+// UserTarget คืนค่า Target ที่ชี้ไปยังผู้ใช้
+func UserTarget(id int) string {
+	return "user:" + strconv.Itoa(id)
+}
+
+// Filter คือเงื่อนไขค้นหา audit log ค่าศูนย์ของแต่ละ field คือไม่กรอง
+type Filter struct {
+	Action  Action
+	ActorID int
+	Target  string
+	// UserID เลือกรายการที่ผู้ใช้เป็นผู้กระทำหรือเป็นเป้าหมาย
+	UserID    int
+	RequestID string
+	Since     time.Time
+	Until     time.Time
+	// BeforeID ใช้แบ่งหน้า: คืนรายการที่ id น้อยกว่าค่านี้
+	BeforeID int64
+	Limit    int
+}
+
+// RequestInfo คือข้อมูลของ HTTP request ที่ทำให้เกิดเหตุการณ์
+type RequestInfo struct {
+	ID        string
+	IP        string
+	UserAgent string
+}
+
+type contextKey int
+
+const (
+	requestKey contextKey = iota
+	actorKey
+)
+
+// WithRequest แนบข้อมูล request ไว้ใน ctx ให้ Record เติมลงรายการเอง
+func WithRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestKey, info)
+}
+
+// RequestFromContext คืนข้อมูล request ที่ WithRequest แนบไว้
+func RequestFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestKey).(RequestInfo)
+	return info, ok
+}
+
+// WithActor แนบผู้กระทำที่ยืนยันตัวตนแล้วไว้ใน ctx
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFromContext คืนผู้กระทำที่ WithActor แนบไว้
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey).(Actor)
+	return actor, ok
+}
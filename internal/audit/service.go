@@ -0,0 +1,118 @@
+// Package audit บันทึกเหตุการณ์ด้านความปลอดภัย (สมัคร, ล็อกอิน, เปลี่ยนรหัสผ่าน, เปลี่ยน role, การกระทำของ admin)
+// ลงตาราง audit_events แบบต่อท้ายอย่างเดียว แต่ละรายการเก็บ hash ของรายการก่อนหน้า (hash chain)
+// การแก้ ลบ หรือแทรกรายการย้อนหลังจึงตรวจพบได้ด้วย Verify
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+	scanBatchSize    = 500
+)
+
+// Recorder คือสิ่งที่ service อื่นใช้บันทึกเหตุการณ์
+type Recorder interface {
+	Record(ctx context.Context, e Entry) error
+}
+
+// Service บันทึก ค้นหา และตรวจสายของ audit log
+// และ implement user.DataSource ให้การ export/ลบบัญชีครอบคลุม audit log
+type Service struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewService คืน service ที่ใช้ store
+func NewService(store Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Record บันทึกเหตุการณ์ โดยเติมเวลา ผู้กระทำ (ถ้ายังไม่ระบุ) และข้อมูล request จาก ctx
+// ถ้า ctx มี transaction รายการจะถูก commit หรือ rollback พร้อมงานนั้น
+func (s *Service) Record(ctx context.Context, e Entry) error {
+	e.OccurredAt = s.now()
+	if e.ActorType == "" {
+		e.ActorType = ActorAnonymous
+		if actor, ok := ActorFromContext(ctx); ok {
+			e.ActorType = actor.Type
+			if actor.Type == ActorUser {
+				id := actor.ID
+				e.ActorID = &id
+			}
+		}
+	}
+	if info, ok := RequestFromContext(ctx); ok {
+		e.RequestID, e.IP, e.UserAgent = info.ID, info.IP, info.UserAgent
+	}
+
+	if _, err := s.store.Append(ctx, e); err != nil {
+		return fmt.Errorf("บันทึก audit log: %w", err)
+	}
+	return nil
+}
+
+// List ค้นหา audit log ล่าสุดก่อน ถ้าไม่ระบุ Limit จะคืน 50 รายการ (สูงสุด 500)
+func (s *Service) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	filter.Limit = min(filter.Limit, maxListLimit)
+	entries, err := s.store.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("ดึง audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Verify ไล่ตรวจทุกรายการตั้งแต่ต้นสาย คืน Report ที่บอกรายการที่ถูกแก้ไข (ถ้ามี)
+func (s *Service) Verify(ctx context.Context) (Report, error) {
+	report := Report{Problems: []Problem{}}
+	for {
+		batch, err := s.store.Scan(ctx, report.LastID, scanBatchSize)
+		if err != nil {
+			return Report{}, fmt.Errorf("อ่าน audit log: %w", err)
+		}
+		for _, e := range batch {
+			report.check(e)
+		}
+		if len(batch) < scanBatchSize {
+			return report, nil
+		}
+	}
+}
+
+// Name คืนชื่อ section ในไฟล์ export (user.DataSource)
+func (s *Service) Name() string {
+	return "audit"
+}
+
+// Export คืนทุกรายการที่ผู้ใช้เป็นผู้กระทำหรือเป้าหมาย
+func (s *Service) Export(ctx context.Context, userID int) (any, error) {
+	var all []Entry
+	filter := Filter{UserID: userID, Limit: scanBatchSize}
+	for {
+		batch, err := s.store.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("ดึง audit log: %w", err)
+		}
+		all = append(all, batch...)
+		if len(batch) < filter.Limit {
+			return all, nil
+		}
+		filter.BeforeID = batch[len(batch)-1].ID
+	}
+}
+
+// Erase ล้างข้อมูลส่วนบุคคลในรายการของผู้ใช้ ตัวรายการและ hash ยังอยู่ สายจึงยังตรวจได้
+// (actor_id และ target ยังชี้ไปยัง ID เดิม ซึ่งไม่เหลือข้อมูลผู้ใช้ให้ย้อนกลับไปหาแล้ว)
+func (s *Service) Erase(ctx context.Context, userID int) error {
+	if _, err := s.store.Erase(ctx, userID, s.now()); err != nil {
+		return fmt.Errorf("ล้างข้อมูลใน audit log: %w", err)
+	}
+	return nil
+}
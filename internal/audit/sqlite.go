@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"fristGoproject/internal/db/sqlite"
+)
+
+// sqliteStore เป็น Store บน SQLite ใช้ฐานเดียวกับ repository อื่นจาก sqlite.Open
+type sqliteStore struct {
+	db *sql.DB
+	tx *sqlite.TxManager
+}
+
+// NewSQLiteStore คืน Store ที่ใช้ตาราง audit_events ในฐาน SQLite
+func NewSQLiteStore(sqldb *sql.DB) Store {
+	return &sqliteStore{db: sqldb, tx: sqlite.NewTxManager(sqldb)}
+}
+
+func (s *sqliteStore) conn(ctx context.Context) sqlite.DBTX {
+	return sqlite.Conn(ctx, s.db)
+}
+
+// Append อ่านหัวสายและเขียนรายการใหม่ใน transaction เดียว
+// transaction ของ SQLite จอง write lock ตั้งแต่ BEGIN การต่อท้ายจึงเกิดทีละรายการอยู่แล้ว
+func (s *sqliteStore) Append(ctx context.Context, e Entry) (Entry, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var prev string
+		err := s.conn(ctx).QueryRowContext(ctx, `SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1`).Scan(&prev)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("read audit chain head: %w", sqlite.Translate(err))
+		}
+		if err := seal(&e, prev); err != nil {
+			return fmt.Errorf("seal audit event: %w", err)
+		}
+		details, err := encodeDetails(e.Details)
+		if err != nil {
+			return err
+		}
+
+		const query = `
+			INSERT INTO audit_events (occurred_at, action, actor_type, actor_id, target, request_id,
+				ip, user_agent, details, pii_salt, pii_hash, prev_hash, hash)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`
+		err = s.conn(ctx).QueryRowContext(ctx, query, sqlite.FormatTime(e.OccurredAt), e.Action, e.ActorType, e.ActorID,
+			e.Target, e.RequestID, e.IP, e.UserAgent, nullText(details), e.PIISalt, e.PIIHash, e.PrevHash, e.Hash).Scan(&e.ID)
+		if err != nil {
+			return fmt.Errorf("insert audit event: %w", sqlite.Translate(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+func (s *sqliteStore) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	where, args := filterClause(filter, func(int) string { return "?" }, func(t time.Time) any { return sqlite.FormatTime(t) })
+	args = append(args, filter.Limit)
+	query := `SELECT ` + entryColumns + ` FROM audit_events` + where + ` ORDER BY id DESC LIMIT ?`
+	return s.query(ctx, query, args...)
+}
+
+func (s *sqliteStore) Scan(ctx context.Context, afterID int64, limit int) ([]Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM audit_events WHERE id > ? ORDER BY id LIMIT ?`
+	return s.query(ctx, query, afterID, limit)
+}
+
+func (s *sqliteStore) Erase(ctx context.Context, userID int, at time.Time) (int64, error) {
+	const query = `
+		UPDATE audit_events
+		SET ip = '', user_agent = '', details = NULL, pii_salt = '', erased_at = ?
+		WHERE (actor_id = ? OR target = ?) AND erased_at IS NULL
+	`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, sqlite.FormatTime(at), userID, UserTarget(userID))
+	if err != nil {
+		return 0, fmt.Errorf("erase audit events: %w", sqlite.Translate(err))
+	}
+	return res.RowsAffected()
+}
+
+func (s *sqliteStore) query(ctx context.Context, query string, args ...any) ([]Entry, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit events: %w", sqlite.Translate(err))
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var (
+			e                 Entry
+			occurredAt        string
+			actorID           sql.NullInt64
+			details, erasedAt sql.NullString
+		)
+		err := rows.Scan(&e.ID, &occurredAt, &e.Action, &e.ActorType, &actorID, &e.Target, &e.RequestID,
+			&e.IP, &e.UserAgent, &details, &e.PIISalt, &e.PIIHash, &e.PrevHash, &e.Hash, &erasedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan audit event: %w", err)
+		}
+		if e.OccurredAt, err = sqlite.ParseTime(occurredAt); err != nil {
+			return nil, err
+		}
+		if e.ErasedAt, err = sqlite.ParseNullTime(erasedAt); err != nil {
+			return nil, err
+		}
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			e.ActorID = &id
+		}
+		if e.Details, err = decodeDetails([]byte(details.String)); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audit events: %w", err)
+	}
+	return entries, nil
+}
+
+// nullText แปลง JSON ว่างเป็น NULL
+func nullText(data []byte) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
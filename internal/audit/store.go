@@ -0,0 +1,207 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/db"
+)
+
+// Store เก็บ audit log แบบต่อท้ายอย่างเดียว ไม่มี method แก้หรือลบรายการ
+// ยกเว้น Erase ที่ล้างได้เฉพาะข้อมูลส่วนบุคคล (ฐานข้อมูลมี trigger กันการแก้ field อื่นอีกชั้น)
+type Store interface {
+	// Append คำนวณ hash ต่อจากรายการล่าสุดแล้วบันทึก คืนรายการที่บันทึกแล้ว
+	// การต่อท้ายถูกทำทีละรายการเพื่อให้สายไม่แตกกิ่งแม้มีหลาย instance
+	Append(ctx context.Context, e Entry) (Entry, error)
+	// List คืนรายการล่าสุดก่อน
+	List(ctx context.Context, filter Filter) ([]Entry, error)
+	// Scan คืนรายการที่ id มากกว่า afterID เรียงจากเก่าไปใหม่ ใช้ตรวจสาย
+	Scan(ctx context.Context, afterID int64, limit int) ([]Entry, error)
+	// Erase ล้าง ip, user_agent, details และ salt ของรายการที่ผู้ใช้เป็นผู้กระทำหรือเป้าหมาย
+	Erase(ctx context.Context, userID int, at time.Time) (int64, error)
+}
+
+// appendLockKey คือ key ของ pg_advisory_xact_lock ที่ทำให้การต่อท้ายสายเกิดทีละรายการ
+const appendLockKey int64 = 0x1e60_a0d1
+
+const entryColumns = `id, occurred_at, action, actor_type, actor_id, target, request_id,
+	ip, user_agent, details, pii_salt, pii_hash, prev_hash, hash, erased_at`
+
+// pgStore เป็น implementation บน Postgres
+type pgStore struct {
+	db db.DBTX
+}
+
+// NewStore คืน Store ที่ใช้ pool ของ primary
+func NewStore(conn db.DBTX) Store {
+	return &pgStore{db: conn}
+}
+
+// Append เปิด transaction ของตัวเอง (หรือ savepoint ถ้า ctx มี transaction อยู่แล้ว)
+// แล้วถือ advisory lock ไว้จนจบ transaction นอกสุด รายการจึงหายไปพร้อมกับงานที่ rollback
+func (s *pgStore) Append(ctx context.Context, e Entry) (Entry, error) {
+	err := pgx.BeginFunc(ctx, db.Conn(ctx, s.db), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockKey); err != nil {
+			return fmt.Errorf("lock audit chain: %w", err)
+		}
+
+		var prev string
+		err := tx.QueryRow(ctx, `SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1`).Scan(&prev)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("read audit chain head: %w", err)
+		}
+		if err := seal(&e, prev); err != nil {
+			return fmt.Errorf("seal audit event: %w", err)
+		}
+		details, err := encodeDetails(e.Details)
+		if err != nil {
+			return err
+		}
+
+		const query = `
+			INSERT INTO audit_events (occurred_at, action, actor_type, actor_id, target, request_id,
+				ip, user_agent, details, pii_salt, pii_hash, prev_hash, hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			RETURNING id
+		`
+		err = tx.QueryRow(ctx, query, e.OccurredAt, e.Action, e.ActorType, e.ActorID, e.Target, e.RequestID,
+			e.IP, e.UserAgent, details, e.PIISalt, e.PIIHash, e.PrevHash, e.Hash).Scan(&e.ID)
+		if err != nil {
+			return fmt.Errorf("insert audit event: %w", db.Translate(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+func (s *pgStore) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	where, args := filterClause(filter, func(n int) string { return fmt.Sprintf("$%d", n) }, func(t time.Time) any { return t })
+	args = append(args, filter.Limit)
+	query := `SELECT ` + entryColumns + ` FROM audit_events` + where + fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+	return s.query(ctx, query, args...)
+}
+
+func (s *pgStore) Scan(ctx context.Context, afterID int64, limit int) ([]Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM audit_events WHERE id > $1 ORDER BY id LIMIT $2`
+	return s.query(ctx, query, afterID, limit)
+}
+
+func (s *pgStore) Erase(ctx context.Context, userID int, at time.Time) (int64, error) {
+	const query = `
+		UPDATE audit_events
+		SET ip = '', user_agent = '', details = NULL, pii_salt = '', erased_at = $3
+		WHERE (actor_id = $1 OR target = $2) AND erased_at IS NULL
+	`
+
+	tag, err := db.Conn(ctx, s.db).Exec(ctx, query, userID, UserTarget(userID), at)
+	if err != nil {
+		return 0, fmt.Errorf("erase audit events: %w", db.Translate(err))
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (s *pgStore) query(ctx context.Context, query string, args ...any) ([]Entry, error) {
+	rows, err := db.Conn(ctx, s.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var (
+			e       Entry
+			details []byte
+		)
+		err := rows.Scan(&e.ID, &e.OccurredAt, &e.Action, &e.ActorType, &e.ActorID, &e.Target, &e.RequestID,
+			&e.IP, &e.UserAgent, &details, &e.PIISalt, &e.PIIHash, &e.PrevHash, &e.Hash, &e.ErasedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan audit event: %w", err)
+		}
+		if e.Details, err = decodeDetails(details); err != nil {
+			return nil, err
+		}
+		e.OccurredAt = e.OccurredAt.UTC()
+		entries = append(entries, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate audit events: %w", rows.Err())
+	}
+	return entries, nil
+}
+
+// filterClause สร้าง WHERE จาก filter ใช้ร่วมกันทั้ง Postgres และ SQLite
+// placeholder คืนตัวแทน argument ลำดับที่ n และ timeArg แปลงเวลาเป็นค่าที่ฐานนั้นเก็บ
+func filterClause(f Filter, placeholder func(n int) string, timeArg func(time.Time) any) (string, []any) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, values ...any) {
+		for _, v := range values {
+			args = append(args, v)
+			cond = strings.Replace(cond, "?", placeholder(len(args)), 1)
+		}
+		where = append(where, cond)
+	}
+	if f.Action != "" {
+		add("action = ?", f.Action)
+	}
+	if f.ActorID > 0 {
+		add("actor_id = ?", f.ActorID)
+	}
+	if f.Target != "" {
+		add("target = ?", f.Target)
+	}
+	if f.UserID > 0 {
+		add("(actor_id = ? OR target = ?)", f.UserID, UserTarget(f.UserID))
+	}
+	if f.RequestID != "" {
+		add("request_id = ?", f.RequestID)
+	}
+	if !f.Since.IsZero() {
+		add("occurred_at >= ?", timeArg(f.Since))
+	}
+	if !f.Until.IsZero() {
+		add("occurred_at < ?", timeArg(f.Until))
+	}
+	if f.BeforeID > 0 {
+		add("id < ?", f.BeforeID)
+	}
+	if len(where) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// encodeDetails คืน nil (NULL) เมื่อไม่มี details
+func encodeDetails(details map[string]any) ([]byte, error) {
+	if len(details) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("encode audit details: %w", err)
+	}
+	return data, nil
+}
+
+func decodeDetails(data []byte) (map[string]any, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var details map[string]any
+	if err := json.Unmarshal(data, &details); err != nil {
+		return nil, fmt.Errorf("decode audit details: %w", err)
+	}
+	return details, nil
+}
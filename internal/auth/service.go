@@ -4,121 +4,251 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
 
+	"fristGoproject/internal/audit"
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/event"
+	"fristGoproject/internal/i18n"
 	"fristGoproject/internal/user"
 	"fristGoproject/pkg/password"
 )
 
 var (
 	// ErrEmailInUse จะถูกส่งกลับเมื่อพยายามสมัครซ้ำอีเมลเดิม
-	ErrEmailInUse = errors.New("email นี้มีผู้ใช้งานแล้ว")
+	ErrEmailInUse = user.ErrDuplicateEmail
 	// ErrInvalidCredentials ใช้กับ logic login หรือ change password
-	ErrInvalidCredentials = errors.New("อีเมลหรือรหัสผ่านไม่ถูกต้อง")
+	ErrInvalidCredentials = i18n.NewError(i18n.MsgInvalidCredentials)
+	// ErrInvalidInput จะถูกส่งกลับเมื่อข้อมูลที่ส่งมาไม่ครบ (error ที่ห่อไว้บอกรายละเอียด)
+	ErrInvalidInput = i18n.NewError(i18n.MsgInvalidInput)
 )
 
 // Service คือชั้นกลางที่เก็บ business logic ของ auth ทั้งหมด
+// ทุก flow อ่านข้อมูลผู้ใช้จาก primary เพื่อไม่ให้ replica ที่ช้ากว่าทำให้ login ด้วยรหัสใหม่ไม่ผ่าน
+// และบันทึก domain event ลง outbox ใน transaction เดียวกับการเปลี่ยนข้อมูล
+// ทุกการสมัคร ล็อกอิน และเปลี่ยนรหัสผ่าน (ทั้งสำเร็จและไม่สำเร็จ) ถูกบันทึกลง audit log
 type Service struct {
-	users user.Repository
+	users  user.Repository
+	tx     db.Transactor
+	events event.Publisher
+	audit  audit.Recorder
 }
 
 // NewService คืน service พร้อมใช้งาน
-func NewService(repo user.Repository) *Service {
-	return &Service{users: repo}
+func NewService(repo user.Repository, tx db.Transactor, events event.Publisher, recorder audit.Recorder) *Service {
+	return &Service{users: repo, tx: tx, events: events, audit: recorder}
 }
 
 // Register สมัครสมาชิกใหม่และคืนข้อมูล user (ไม่รวม password hash)
 // rawPassword ควรเป็นสตริง SHA-256 hex ที่ client แปลงมาก่อน (หรือรูปแบบที่เตรียมไว้)
 func (s *Service) Register(ctx context.Context, email, rawPassword, name string) (user.User, error) {
+	ctx = db.WithPrimary(ctx)
 	email = strings.TrimSpace(strings.ToLower(email))
 	rawPassword = strings.TrimSpace(rawPassword)
 	name = strings.TrimSpace(name)
-	if email == "" || rawPassword == "" {
-		return user.User{}, errors.New("email และ password ต้องไม่ว่าง")
+	if email == "" {
+		return user.User{}, fmt.Errorf("%w: %w", ErrInvalidInput, i18n.NewError(i18n.MsgFieldRequired, "email"))
+	}
+	if rawPassword == "" {
+		return user.User{}, fmt.Errorf("%w: %w", ErrInvalidInput, i18n.NewError(i18n.MsgFieldRequired, "password"))
 	}
 
+	// ตรวจก่อนเพื่อไม่ต้องเสียเวลา Argon2 กับอีเมลที่มีอยู่แล้ว
+	// ถ้าสมัครพร้อมกันจนหลุดการตรวจนี้ unique constraint ของฐานจะกันไว้ที่ Create อีกชั้น
 	if _, err := s.users.FindByEmail(ctx, email); err == nil {
 		return user.User{}, ErrEmailInUse
 	} else if !errors.Is(err, pgx.ErrNoRows) {
 		return user.User{}, fmt.Errorf("ตรวจสอบอีเมลซ้ำ: %w", err)
 	}
 
-	hash, err := password.HashPassword(rawPassword)
+	hash, err := password.HashPassword(ctx, rawPassword)
 	if err != nil {
 		return user.User{}, fmt.Errorf("hash password: %w", err)
 	}
 
-	newUser := user.User{
-		Email:        email,
-		PasswordHash: hash,
-		Name:         name,
+	var created user.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.users.Create(ctx, user.User{
+			Email:        email,
+			PasswordHash: hash,
+			Name:         name,
+		})
+		if err != nil {
+			if errors.Is(err, user.ErrDuplicateEmail) {
+				return ErrEmailInUse
+			}
+			return fmt.Errorf("สร้างผู้ใช้: %w", err)
+		}
+		err = s.audit.Record(ctx, audit.Entry{
+			Action:  audit.UserRegistered,
+			Target:  audit.UserTarget(created.ID),
+			Details: map[string]any{"email": created.Email},
+		})
+		if err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, event.New(event.UserRegistered, created.ID, user.RegisteredEvent{
+			Email:  created.Email,
+			Name:   created.Name,
+			Source: "register",
+		}))
+	})
+	if err != nil {
+		return user.User{}, err
 	}
+	created.PasswordHash = "" // ไม่ส่ง hash กลับไปยัง handler
+	return created, nil
+}
 
+// Login ตรวจสอบ email/password ที่ client ส่ง (หลังเข้ารหัส SHA-256) แล้วคืนข้อมูลผู้ใช้หากสำเร็จ
+// และบันทึก event user.logged_in พร้อม audit log
+// ถ้า hash ที่เก็บไว้อ่อนกว่าค่าปัจจุบันจะ hash ใหม่ด้วยรหัสผ่านนี้แล้วบันทึกทับ (ไม่สำเร็จก็ยังล็อกอินได้)
+func (s *Service) Login(ctx context.Context, email, rawPassword string) (user.User, error) {
+	ctx = db.WithPrimary(ctx)
+	rawPassword = strings.TrimSpace(rawPassword)
+	u, err := s.authenticate(ctx, email, rawPassword)
+	if err != nil {
+		return user.User{}, err
+	}
+	if password.NeedsRehash(u.PasswordHash) {
+		if err := s.rehash(ctx, u, rawPassword); err != nil {
+			log.Printf("rehash password of user %d: %v", u.ID, err)
+		}
+	}
+	u.PasswordHash = ""
 
-
-	if err := s.users.Create(ctx, newUser); err != nil {
-		return user.User{}, fmt.Errorf("สร้างผู้ใช้: %w", err)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.audit.Record(ctx, userEntry(audit.LoginSucceeded, u.ID)); err != nil {
+			return err
+		}
+		if err := s.events.Publish(ctx, event.New(event.UserLoggedIn, u.ID, nil)); err != nil {
+			return fmt.Errorf("บันทึก event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return user.User{}, err
 	}
+	return u, nil
+}
 
-	// ดึงข้อมูลกลับจากฐาน เพื่อให้ได้ id & created_at
-	created, err := s.users.FindByEmail(ctx, email)
+// Authenticate ตรวจ email/password เหมือน Login แต่ไม่บันทึก event และไม่บันทึกความสำเร็จลง audit log
+// ใช้กับการยืนยันตัวตนที่ทำทุก request (Basic auth) ไม่ให้ทุกการเรียก API กลายเป็น event login
+// ส่วนการยืนยันตัวตนที่ไม่ผ่านถูกบันทึกเสมอ เพื่อให้เห็นการเดารหัสผ่านทั้งผ่าน Login และ Basic auth
+func (s *Service) Authenticate(ctx context.Context, email, rawPassword string) (user.User, error) {
+	u, err := s.authenticate(db.WithPrimary(ctx), email, strings.TrimSpace(rawPassword))
 	if err != nil {
-		return user.User{}, fmt.Errorf("ดึงข้อมูลผู้ใช้: %w", err)
+		return user.User{}, err
 	}
-	created.PasswordHash = "" // ไม่ส่ง hash กลับไปยัง handler
-	return created, nil
+	u.PasswordHash = ""
+	return u, nil
 }
 
-// Login ตรวจสอบ email/password ที่ client ส่ง (หลังเข้ารหัส SHA-256) แล้วคืนข้อมูลผู้ใช้หากสำเร็จ
-func (s *Service) Login(ctx context.Context, email, rawPassword string) (user.User, error) {
+// authenticate คืนผู้ใช้พร้อม PasswordHash ให้ Login ตรวจต่อว่าต้อง rehash หรือไม่
+func (s *Service) authenticate(ctx context.Context, email, rawPassword string) (user.User, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
-	rawPassword = strings.TrimSpace(rawPassword)
 	u, err := s.users.FindByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return user.User{}, ErrInvalidCredentials
+			return user.User{}, s.reject(ctx, audit.LoginFailed, email, 0, "unknown_email")
 		}
 		return user.User{}, fmt.Errorf("ค้นหาผู้ใช้: %w", err)
 	}
 
-	if err := password.CheckPassword(u.PasswordHash, rawPassword); err != nil {
-		return user.User{}, ErrInvalidCredentials
+	if err := password.CheckPassword(ctx, u.PasswordHash, rawPassword); err != nil {
+		if unavailable(err) {
+			return user.User{}, fmt.Errorf("ตรวจรหัสผ่าน: %w", err)
+		}
+		return user.User{}, s.reject(ctx, audit.LoginFailed, email, u.ID, "wrong_password")
 	}
-
-	u.PasswordHash = ""
 	return u, nil
 }
 
+// rehash บันทึก hash ใหม่ของรหัสผ่านที่เพิ่งตรวจผ่าน ด้วยค่าจาก password.CurrentParams
+// เขียนด้วย ReplacePassword ที่เทียบ hash เดิมในคำสั่ง UPDATE ถ้าผู้ใช้เปลี่ยนรหัสผ่านไประหว่างนั้นจะไม่เขียนทับ
+func (s *Service) rehash(ctx context.Context, u user.User, rawPassword string) error {
+	hash, err := password.HashPassword(ctx, rawPassword)
+	if err != nil {
+		return err
+	}
+	_, err = s.users.ReplacePassword(ctx, u.ID, u.PasswordHash, hash)
+	return err
+}
+
 // ChangePassword ตรวจสอบรหัสเดิม (รูปแบบเดียวกับที่ client ส่งให้ เช่น SHA-256) ก่อนบันทึกรหัสใหม่
 func (s *Service) ChangePassword(ctx context.Context, email, oldPassword, newPassword string) error {
+	ctx = db.WithPrimary(ctx)
 	email = strings.TrimSpace(strings.ToLower(email))
 	oldPassword = strings.TrimSpace(oldPassword)
 	newPassword = strings.TrimSpace(newPassword)
 	if newPassword == "" {
-		return errors.New("รหัสผ่านใหม่ต้องไม่ว่าง")
+		return fmt.Errorf("%w: %w", ErrInvalidInput, i18n.NewError(i18n.MsgFieldRequired, "new_password"))
 	}
 
 	u, err := s.users.FindByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrInvalidCredentials
+			return s.reject(ctx, audit.PasswordChangeFailed, email, 0, "unknown_email")
 		}
 		return fmt.Errorf("ค้นหาผู้ใช้: %w", err)
 	}
 
-	if err := password.CheckPassword(u.PasswordHash, oldPassword); err != nil {
-		return ErrInvalidCredentials
+	if err := password.CheckPassword(ctx, u.PasswordHash, oldPassword); err != nil {
+		if unavailable(err) {
+			return fmt.Errorf("ตรวจรหัสผ่าน: %w", err)
+		}
+		return s.reject(ctx, audit.PasswordChangeFailed, email, u.ID, "wrong_password")
 	}
 
-	hash, err := password.HashPassword(newPassword)
+	hash, err := password.HashPassword(ctx, newPassword)
 	if err != nil {
 		return fmt.Errorf("hash password: %w", err)
 	}
 
-	if err := s.users.UpdatePassword(ctx, u.ID, hash); err != nil {
-		return fmt.Errorf("อัปเดตรหัสผ่าน: %w", err)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.users.UpdatePassword(ctx, u.ID, hash); err != nil {
+			return fmt.Errorf("อัปเดตรหัสผ่าน: %w", err)
+		}
+		if err := s.audit.Record(ctx, userEntry(audit.PasswordChanged, u.ID)); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, event.New(event.UserPasswordChanged, u.ID, nil))
+	})
+}
+
+// unavailable บอกว่า CheckPassword ตรวจไม่ได้เพราะคิว hash เต็ม คำขอถูกยกเลิก หรือไม่ได้ตั้ง pepper ที่ hash อ้างถึง
+// กรณีนี้ไม่ใช่รหัสผิด จึงไม่บันทึกเป็นการยืนยันตัวตนที่ไม่ผ่าน
+func unavailable(err error) bool {
+	return errors.Is(err, password.ErrBusy) || errors.Is(err, password.ErrUnknownPepper) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// userEntry คือรายการ audit ที่ผู้ใช้ทำกับบัญชีของตัวเองหลังยืนยันรหัสผ่านแล้ว
+func userEntry(action audit.Action, userID int) audit.Entry {
+	return audit.Entry{
+		Action:    action,
+		ActorType: audit.ActorUser,
+		ActorID:   &userID,
+		Target:    audit.UserTarget(userID),
+	}
+}
+
+// reject บันทึกการยืนยันตัวตนที่ไม่ผ่านลง audit log แล้วคืน ErrInvalidCredentials
+// userID เป็น 0 เมื่อไม่พบอีเมล (ถ้าบันทึกไม่สำเร็จจะคืน error นั้นแทน)
+func (s *Service) reject(ctx context.Context, action audit.Action, email string, userID int, reason string) error {
+	e := audit.Entry{
+		Action:  action,
+		Details: map[string]any{"email": email, "reason": reason},
+	}
+	if userID != 0 {
+		e.Target = audit.UserTarget(userID)
+	}
+	if err := s.audit.Record(ctx, e); err != nil {
+		return err
 	}
-	return nil
+	return ErrInvalidCredentials
 }
@@ -0,0 +1,120 @@
+package avatar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// ค่า EXIF Orientation (tag 0x0112) ตามที่กล้องบันทึกไว้ 1 คือภาพตั้งตรงอยู่แล้ว
+const (
+	orientNormal     = 1
+	orientFlipH      = 2
+	orientRotate180  = 3
+	orientFlipV      = 4
+	orientTranspose  = 5
+	orientRotate90   = 6
+	orientTransverse = 7
+	orientRotate270  = 8
+)
+
+// jpegOrientation อ่านค่า Orientation จาก segment APP1 (Exif) ของไฟล์ JPEG
+// ไฟล์ชนิดอื่นหรือ EXIF ที่อ่านไม่ได้ถือว่าตั้งตรง (1)
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return orientNormal
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return orientNormal
+		}
+		marker := data[i+1]
+		// SOS หรือ EOI: เลยส่วน metadata มาแล้ว
+		if marker == 0xDA || marker == 0xD9 {
+			return orientNormal
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return orientNormal
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return orientNormal
+}
+
+// exifOrientation หา tag Orientation ใน IFD0 ของข้อมูล TIFF ที่อยู่ใน EXIF
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return orientNormal
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return orientNormal
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return orientNormal
+	}
+	entries := int(order.Uint16(tiff[offset:]))
+	for k := range entries {
+		entry := offset + 2 + k*12
+		if entry+12 > len(tiff) {
+			return orientNormal
+		}
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		if v := int(order.Uint16(tiff[entry+8:])); v >= orientNormal && v <= orientRotate270 {
+			return v
+		}
+		return orientNormal
+	}
+	return orientNormal
+}
+
+// applyOrientation หมุน/กลับภาพให้ตั้งตรงตามค่า Orientation
+// เรียกกับภาพที่ย่อแล้ว (เล็ก) เพราะการหมุนสลับลำดับกับการตัดสี่เหลี่ยมจัตุรัสตรงกลางได้
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= orientNormal || orientation > orientRotate270 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= orientTranspose {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		for x := range dw {
+			var sx, sy int
+			switch orientation {
+			case orientFlipH:
+				sx, sy = w-1-x, y
+			case orientRotate180:
+				sx, sy = w-1-x, h-1-y
+			case orientFlipV:
+				sx, sy = x, h-1-y
+			case orientTranspose:
+				sx, sy = y, x
+			case orientRotate90:
+				sx, sy = y, h-1-x
+			case orientTransverse:
+				sx, sy = w-1-y, h-1-x
+			case orientRotate270:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, src.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
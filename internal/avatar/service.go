@@ -0,0 +1,209 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // ลงทะเบียน decoder ให้ image.Decode
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/storage"
+	"fristGoproject/internal/user"
+)
+
+const (
+	// MaxUploadSize คือขนาดไฟล์สูงสุดที่รับ (5 MiB)
+	MaxUploadSize = 5 << 20
+	// maxDimension กันไฟล์เล็กแต่ประกาศขนาดภาพใหญ่มาก (decompression bomb)
+	// 4096x4096 ใช้หน่วยความจำตอน decode ราว 64 MiB ต่อรูป
+	maxDimension = 4096
+	// maxConcurrentDecodes จำกัดจำนวนรูปที่ decode พร้อมกัน คำขอที่เกินจะรอคิว
+	maxConcurrentDecodes = 2
+	// mainSize คือขนาดที่ใช้เป็น avatar_url ของผู้ใช้
+	mainSize    = 256
+	jpegQuality = 85
+)
+
+// sizes คือขนาด (px, สี่เหลี่ยมจัตุรัส) ที่สร้างทุกครั้งที่อัปโหลด
+var sizes = []int{512, mainSize, 64}
+
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+var (
+	// ErrUnsupportedType จะถูกส่งกลับเมื่อไฟล์ไม่ใช่รูปชนิดที่รองรับ
+	ErrUnsupportedType = i18n.NewError(i18n.MsgUnsupportedImage)
+	// ErrInvalidImage จะถูกส่งกลับเมื่ออ่านรูปไม่ได้หรือขนาดภาพเกินกำหนด
+	ErrInvalidImage = i18n.NewError(i18n.MsgInvalidImage)
+)
+
+// Service จัดการรูปโปรไฟล์: ตรวจไฟล์, ตัด metadata (EXIF), ย่อขนาด แล้วเก็บผ่าน BlobStore
+type Service struct {
+	store storage.BlobStore
+	users user.Repository
+	now   func() time.Time
+	// decodes เป็น semaphore ของการ decode รูป (ขนาด maxConcurrentDecodes)
+	decodes chan struct{}
+}
+
+// NewService คืน service ที่เก็บไฟล์ลง store และบันทึก URL ลง users
+func NewService(store storage.BlobStore, users user.Repository) *Service {
+	return &Service{store: store, users: users, now: time.Now, decodes: make(chan struct{}, maxConcurrentDecodes)}
+}
+
+// Upload ประมวลผลรูปที่ผู้ใช้ส่งมาแล้วอัปเดต avatar_url คืนข้อมูลผู้ใช้ล่าสุด
+// รูปทุกขนาดถูก encode ใหม่เป็น JPEG ทำให้ EXIF และ metadata อื่นหลุดไปทั้งหมด
+// จึงหมุนภาพตาม EXIF Orientation ก่อน ไม่อย่างนั้นรูปจากมือถือจะตะแคง
+func (s *Service) Upload(ctx context.Context, userID int, data []byte) (user.User, error) {
+	if len(data) > MaxUploadSize {
+		return user.User{}, fmt.Errorf("%w: ขนาดไฟล์เกิน %d ไบต์", ErrInvalidImage, MaxUploadSize)
+	}
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return user.User{}, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return user.User{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width > maxDimension || cfg.Height > maxDimension {
+		return user.User{}, fmt.Errorf("%w: ขนาดภาพต้องไม่เกิน %dx%d", ErrInvalidImage, maxDimension, maxDimension)
+	}
+
+	select {
+	case s.decodes <- struct{}{}:
+		defer func() { <-s.decodes }()
+	case <-ctx.Done():
+		return user.User{}, ctx.Err()
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return user.User{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	square := cropSquare(src)
+	orientation := jpegOrientation(data)
+
+	for _, size := range sizes {
+		encoded, err := encodeJPEG(applyOrientation(resize(square, size), orientation))
+		if err != nil {
+			return user.User{}, fmt.Errorf("encode รูป %dpx: %w", size, err)
+		}
+		if err := s.store.Put(ctx, objectKey(userID, size), encoded, "image/jpeg"); err != nil {
+			return user.User{}, fmt.Errorf("บันทึกรูป %dpx: %w", size, err)
+		}
+	}
+
+	// ใส่ version ต่อท้ายเพื่อให้ cache ของ browser/CDN ไม่ค้างรูปเก่า (key เดิมถูกเขียนทับ)
+	url := fmt.Sprintf("%s?v=%d", s.store.URL(objectKey(userID, mainSize)), s.now().Unix())
+	if err := s.users.UpdateAvatar(ctx, userID, url); err != nil {
+		return user.User{}, fmt.Errorf("บันทึก avatar_url: %w", err)
+	}
+
+	u, err := s.users.FindByID(db.WithPrimary(ctx), userID)
+	if err != nil {
+		return user.User{}, fmt.Errorf("ดึงข้อมูลผู้ใช้: %w", err)
+	}
+	u.PasswordHash = ""
+	return u, nil
+}
+
+// Remove ลบไฟล์ทุกขนาดและล้าง avatar_url
+func (s *Service) Remove(ctx context.Context, userID int) error {
+	if err := s.deleteObjects(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.users.UpdateAvatar(ctx, userID, ""); err != nil {
+		return fmt.Errorf("ล้าง avatar_url: %w", err)
+	}
+	return nil
+}
+
+// Name ใช้เป็นชื่อ section ใน user export (implement user.DataSource)
+func (s *Service) Name() string {
+	return "avatar"
+}
+
+// Export คืน URL ของรูปทุกขนาดที่เก็บไว้ (implement user.DataSource)
+func (s *Service) Export(ctx context.Context, userID int) (any, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ค้นหาผู้ใช้: %w", err)
+	}
+	if u.AvatarURL == "" {
+		return nil, nil
+	}
+
+	files := make(map[string]string, len(sizes))
+	for _, size := range sizes {
+		files[fmt.Sprintf("%dpx", size)] = s.store.URL(objectKey(userID, size))
+	}
+	return map[string]any{"avatar_url": u.AvatarURL, "files": files}, nil
+}
+
+// Erase ลบไฟล์รูปทั้งหมดของผู้ใช้ (implement user.DataSource)
+func (s *Service) Erase(ctx context.Context, userID int) error {
+	return s.deleteObjects(ctx, userID)
+}
+
+func (s *Service) deleteObjects(ctx context.Context, userID int) error {
+	for _, size := range sizes {
+		if err := s.store.Delete(ctx, objectKey(userID, size)); err != nil {
+			return fmt.Errorf("ลบรูป %dpx: %w", size, err)
+		}
+	}
+	return nil
+}
+
+func objectKey(userID, size int) string {
+	return fmt.Sprintf("avatars/%d/%d.jpg", userID, size)
+}
+
+// cropSquare ตัดกึ่งกลางภาพให้เป็นสี่เหลี่ยมจัตุรัส
+func cropSquare(src image.Image) image.Image {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	rect := image.Rect(x0, y0, x0+side, y0+side)
+
+	if sub, ok := src.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), src, rect.Min, draw.Src)
+	return dst
+}
+
+// resize ย่อ/ขยายเป็น size x size บนพื้นขาว (JPEG ไม่มี alpha จึงต้องถมพื้นก่อน)
+func resize(src image.Image, size int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+	return dst
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Cluster รวม pool ของ primary กับ read replica (ถ้ามี)
+// งานเขียนทั้งหมดต้องใช้ Primary ส่วนงานอ่านที่ยอมรับข้อมูลช้าได้เล็กน้อยให้ใช้ Reader
+type Cluster struct {
+	Primary *pgxpool.Pool
+
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint64
+	stop     context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+type primaryKey struct{}
+
+// WithPrimary บังคับให้การอ่านทุกครั้งที่ใช้ ctx นี้ไปที่ primary
+// ใช้กับ flow ที่ต้องเห็นข้อมูลล่าสุดเสมอ เช่น login หรือการอ่านหลังเขียน
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsePrimary บอกว่า ctx ถูกตรึงไว้กับ primary หรือไม่
+func UsePrimary(ctx context.Context) bool {
+	pinned, _ := ctx.Value(primaryKey{}).(bool)
+	return pinned
+}
+
+// Reader คืน pool สำหรับงานอ่าน สลับกันระหว่าง replica ที่ lag ไม่เกินกำหนด
+// ถ้าไม่มี replica ที่พร้อม หรือ ctx ถูกตรึงด้วย WithPrimary จะคืน Primary
+func (c *Cluster) Reader(ctx context.Context) *pgxpool.Pool {
+	if len(c.replicas) == 0 || UsePrimary(ctx) {
+		return c.Primary
+	}
+	start := c.next.Add(1)
+	for i := range c.replicas {
+		r := c.replicas[(int(start)+i)%len(c.replicas)]
+		if r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return c.Primary
+}
+
+// Close หยุดตัวตรวจ lag แล้วปิดทุก pool
+func (c *Cluster) Close() {
+	if c.stop != nil {
+		c.stop()
+		c.wg.Wait()
+	}
+	for _, r := range c.replicas {
+		r.pool.Close()
+	}
+	c.Primary.Close()
+}
+
+// lagQuery คืน lag ของ replica เป็นวินาที ถ้าเป็นฐานที่ไม่ได้อยู่ใน recovery (ไม่ใช่ replica) ถือว่า lag เป็นศูนย์
+// เมื่อ replay ตาม WAL ที่ได้รับทันแล้วก็ถือว่าไม่ lag แม้ primary จะไม่มี transaction ใหม่มานาน
+const lagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END::float8
+`
+
+// openReplicas เปิด pool ของทุก replica แบบไม่รอให้เชื่อมต่อได้
+// replica ที่ยังไม่พร้อมจะถูกข้ามจนกว่าตัวตรวจ lag จะเห็นว่าใช้ได้ จึงไม่ทำให้ระบบเริ่มไม่ได้
+func (c *Cluster) openReplicas(cfg Config) error {
+	for i, url := range cfg.ReplicaURLs {
+		poolCfg, err := poolConfig(cfg, url)
+		if err != nil {
+			c.Close()
+			return err
+		}
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
+		if err != nil {
+			c.Close()
+			return err
+		}
+		c.replicas = append(c.replicas, &replica{name: replicaName(i, poolCfg), pool: pool})
+	}
+	if len(c.replicas) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stop = cancel
+	c.checkReplicas(ctx, cfg.ReplicaCheckPeriod)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(cfg.ReplicaCheckPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.checkReplicas(ctx, cfg.ReplicaCheckPeriod)
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *Cluster) checkReplicas(ctx context.Context, timeout time.Duration) {
+	for _, r := range c.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		var lagSeconds *float64
+		err := r.pool.QueryRow(checkCtx, lagQuery).Scan(&lagSeconds)
+		cancel()
+
+		var healthy bool
+		var reason string
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case lagSeconds == nil:
+			reason = "replay position unknown"
+		default:
+			lag := time.Duration(*lagSeconds * float64(time.Second))
+			healthy = lag <= c.maxLag
+			if !healthy {
+				reason = "lag " + lag.Round(time.Millisecond).String()
+			}
+		}
+
+		if was := r.healthy.Swap(healthy); was != healthy {
+			if healthy {
+				log.Printf("replica %s in rotation", r.name)
+			} else {
+				log.Printf("replica %s out of rotation: %s", r.name, reason)
+			}
+		}
+	}
+}
+
+func replicaName(i int, poolCfg *pgxpool.Config) string {
+	return fmt.Sprintf("#%d (%s)", i+1, poolCfg.ConnConfig.Host)
+}
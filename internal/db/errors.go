@@ -0,0 +1,63 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE ที่ repository ต้องแยกแยะ (https://www.postgresql.org/docs/current/errcodes-appendix.html)
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeSerializationFailure = "40001"
+)
+
+var (
+	// ErrUniqueViolation คือข้อมูลซ้ำกับ unique constraint
+	ErrUniqueViolation = errors.New("unique violation")
+	// ErrForeignKeyViolation คือแถวที่อ้างถึงไม่มีอยู่ (หรือยังถูกอ้างถึงอยู่ตอนลบ)
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	// ErrSerialization คือ transaction ชนกับ transaction อื่น ผู้เรียกลองใหม่ได้
+	ErrSerialization = errors.New("serialization failure")
+)
+
+// ConstraintError คือ error จาก Postgres ที่แปลงเป็น sentinel ของ package นี้แล้ว
+// errors.Is ใช้กับ Kind ได้ และ errors.As ยังได้ *pgconn.PgError ตัวเดิม
+type ConstraintError struct {
+	Kind       error
+	Constraint string
+	Err        *pgconn.PgError
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Constraint == "" {
+		return fmt.Sprintf("%v: %s", e.Kind, e.Err.Message)
+	}
+	return fmt.Sprintf("%v on %s: %s", e.Kind, e.Constraint, e.Err.Message)
+}
+
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Translate แปลง *pgconn.PgError ที่รู้จักเป็น *ConstraintError ค่าอื่นคืนกลับไปตามเดิม
+func Translate(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	var kind error
+	switch pgErr.Code {
+	case codeUniqueViolation:
+		kind = ErrUniqueViolation
+	case codeForeignKeyViolation:
+		kind = ErrForeignKeyViolation
+	case codeSerializationFailure:
+		kind = ErrSerialization
+	default:
+		return err
+	}
+	return &ConstraintError{Kind: kind, Constraint: pgErr.ConstraintName, Err: pgErr}
+}
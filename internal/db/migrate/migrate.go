@@ -0,0 +1,318 @@
+// Package migrate จัดการ schema ของฐานข้อมูลด้วยไฟล์ SQL ที่ฝังมากับ binary
+// ไฟล์ตั้งชื่อแบบ <version>_<name>.up.sql และ <version>_<name>.down.sql
+// version ที่รันแล้วถูกบันทึกใน schema_migrations พร้อม checksum ของไฟล์ up
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// lockKey คือ key ของ pg_advisory_lock ที่ใช้กันหลาย replica migrate พร้อมกัน
+// เป็นค่าคงที่ตายตัว ทุก instance ของ service นี้ต้องใช้ค่าเดียวกัน
+const lockKey int64 = 0x1e60_4d16
+
+// ErrChecksumMismatch จะถูกส่งกลับเมื่อไฟล์ migration ที่รันไปแล้วถูกแก้ไขภายหลัง
+var ErrChecksumMismatch = errors.New("ไฟล์ migration ถูกแก้ไขหลังจากรันไปแล้ว")
+
+// Migration คือ schema change หนึ่งเวอร์ชัน
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status คือสถานะของ migration หนึ่งเวอร์ชันเทียบกับฐานข้อมูล
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Drift เป็น true เมื่อ checksum ในฐานไม่ตรงกับไฟล์ปัจจุบัน
+	Drift bool `json:"drift,omitempty"`
+}
+
+type appliedRow struct {
+	version   int64
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator รัน migration กับฐานข้อมูล Postgres
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// New คืน Migrator ที่ใช้ไฟล์ migration ที่ฝังมากับ binary
+func New(pool *pgxpool.Pool) (*Migrator, error) {
+	sub, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return NewFromFS(pool, sub)
+}
+
+// NewFromFS คืน Migrator ที่อ่านไฟล์ migration จาก fsys (ไฟล์อยู่ที่ root ของ fsys)
+func NewFromFS(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Load อ่านและจับคู่ไฟล์ up/down ทั้งหมด เรียงตาม version
+// ทุก version ต้องมีไฟล์ up ส่วน down ไม่มีก็ได้ (ย้อนกลับไม่ได้)
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("อ่านโฟลเดอร์ migration: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		version, name, direction, err := parseFilename(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("อ่าน %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("version %d มีสองชื่อ: %s และ %s", version, m.Name, name)
+		}
+
+		switch direction {
+		case "up":
+			m.Up = string(data)
+			sum := sha256.Sum256(data)
+			m.Checksum = hex.EncodeToString(sum[:])
+		case "down":
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("version %d (%s) ไม่มีไฟล์ up", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseFilename แยก "0002_add_user_attributes.up.sql" เป็น (2, "add_user_attributes", "up")
+func parseFilename(filename string) (int64, string, string, error) {
+	base := strings.TrimSuffix(filename, ".sql")
+	direction := path.Ext(base)
+	base = strings.TrimSuffix(base, direction)
+	direction = strings.TrimPrefix(direction, ".")
+	if direction != "up" && direction != "down" {
+		return 0, "", "", fmt.Errorf("ชื่อไฟล์ %s ต้องลงท้ายด้วย .up.sql หรือ .down.sql", filename)
+	}
+
+	rawVersion, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", "", fmt.Errorf("ชื่อไฟล์ %s ต้องเป็น <version>_<name>", filename)
+	}
+	version, err := strconv.ParseInt(rawVersion, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("version ในชื่อไฟล์ %s ต้องเป็นตัวเลขบวก", filename)
+	}
+	return version, name, direction, nil
+}
+
+// Up รัน migration ทุกเวอร์ชันที่ยังไม่ได้รัน คืนรายการที่รันในครั้งนี้
+// ถ้าพบว่าไฟล์ที่รันไปแล้วถูกแก้ (checksum drift) จะหยุดโดยไม่รันอะไรเพิ่ม
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkDrift(applied); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					mig.Version, mig.Name, mig.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrate up %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down ย้อน migration ล่าสุดกลับ steps เวอร์ชัน คืนรายการที่ย้อนในครั้งนี้
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps ต้องมากกว่า 0")
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkDrift(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("version %d (%s) ไม่มีไฟล์ down ย้อนกลับไม่ได้", mig.Version, mig.Name)
+			}
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrate down %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status คืนสถานะของทุก migration ที่รู้จัก (รวมที่ยังไม่ได้รัน)
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var out []Status
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			st := Status{Version: mig.Version, Name: mig.Name}
+			if row, ok := applied[mig.Version]; ok {
+				at := row.appliedAt
+				st.AppliedAt = &at
+				st.Drift = row.checksum != mig.Checksum
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// withLock ยืม connection หนึ่งเส้นแล้วถือ advisory lock ไว้ตลอดการทำงานของ fn
+// replica อื่นที่เริ่มพร้อมกันจะรอจนกว่าตัวแรก migrate เสร็จ แล้วค่อยพบว่าไม่มีอะไรต้องรัน
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("advisory lock: %w", err)
+	}
+	defer func() {
+		// ใช้ context ใหม่เพื่อปลด lock ได้แม้ ctx เดิมถูกยกเลิกไปแล้ว
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, lockKey)
+	}()
+
+	const createTable = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`
+	if _, err := conn.Exec(ctx, createTable); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *pgxpool.Conn) (map[int64]appliedRow, error) {
+	rows, err := conn.Query(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedRow{}
+	for rows.Next() {
+		var row appliedRow
+		if err := rows.Scan(&row.version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("scan schema_migrations: %w", err)
+		}
+		applied[row.version] = row
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate schema_migrations: %w", rows.Err())
+	}
+	return applied, nil
+}
+
+// checkDrift ตรวจว่าไฟล์ของทุกเวอร์ชันที่รันไปแล้วไม่ถูกแก้
+// version ในฐานที่ binary นี้ไม่รู้จักจะถูกข้าม (เกิดได้ตอน rolling deploy ที่ pod รุ่นเก่ายังรันอยู่)
+func (m *Migrator) checkDrift(applied map[int64]appliedRow) error {
+	for _, mig := range m.migrations {
+		row, ok := applied[mig.Version]
+		if ok && row.checksum != mig.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, mig.Version, mig.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+-- IF NOT EXISTS เพื่อให้ฐานที่เคยสร้างตารางเองตามตัวอย่างใน README ย้ายมาใช้ migration ได้เลย
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    email TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
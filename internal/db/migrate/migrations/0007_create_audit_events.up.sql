@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    action TEXT NOT NULL,
+    actor_type TEXT NOT NULL,
+    actor_id INT,
+    target TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details JSONB,
+    pii_salt TEXT NOT NULL DEFAULT '',
+    pii_hash TEXT NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL UNIQUE,
+    erased_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor_id, id);
+CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target, id);
+CREATE INDEX IF NOT EXISTS audit_events_action_idx ON audit_events (action, id);
+
+-- audit log ต่อท้ายได้อย่างเดียว: ห้ามลบ และแก้ได้เฉพาะการล้างข้อมูลส่วนบุคคลครั้งเดียวตอนลบบัญชี
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        RAISE EXCEPTION 'audit_events is append-only';
+    END IF;
+    IF OLD.erased_at IS NOT NULL OR NEW.erased_at IS NULL
+        OR NEW.ip <> '' OR NEW.user_agent <> '' OR NEW.details IS NOT NULL OR NEW.pii_salt <> ''
+        OR (NEW.id, NEW.occurred_at, NEW.action, NEW.actor_type, NEW.target, NEW.request_id,
+            NEW.pii_hash, NEW.prev_hash, NEW.hash)
+            IS DISTINCT FROM (OLD.id, OLD.occurred_at, OLD.action, OLD.actor_type, OLD.target, OLD.request_id,
+            OLD.pii_hash, OLD.prev_hash, OLD.hash)
+        OR NEW.actor_id IS DISTINCT FROM OLD.actor_id THEN
+        RAISE EXCEPTION 'audit_events is append-only: only personal data may be erased';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
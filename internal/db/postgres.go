@@ -3,20 +3,68 @@ package db
 import (
 	"context"
 	"fmt"
-	"os"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// Connect opens a pgx connection pool using DATABASE_URL or a sensible default.
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 10 * time.Second
+)
+
+// Connect opens the primary pool from cfg, retrying with exponential backoff
+// until Postgres accepts connections or cfg.ConnectTimeout elapses,
+// then opens a pool for every replica in cfg.ReplicaURLs.
 // กำหนดให้เรียกใช้ตอนเริ่มโปรแกรม เพื่อให้มี pool สำหรับใช้ทั้งระบบ
-func Connect(ctx context.Context) (*pgxpool.Pool, error) {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		// ค่า default สำหรับเครื่องของคุณ ปรับได้ตามต้องการ
-		dsn = "postgres://in:in@localhost:5432/lindb"
+func Connect(ctx context.Context, cfg Config) (*Cluster, error) {
+	primary, err := connectPrimary(ctx, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	pool, err := pgxpool.New(ctx, dsn)
+	cluster := &Cluster{Primary: primary, maxLag: cfg.MaxReplicaLag}
+	if err := cluster.openReplicas(cfg); err != nil {
+		return nil, fmt.Errorf("open replica: %w", err)
+	}
+	return cluster, nil
+}
+
+func connectPrimary(ctx context.Context, cfg Config) (*pgxpool.Pool, error) {
+	poolCfg, err := poolConfig(cfg, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(cfg.ConnectTimeout)
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		pool, err := open(ctx, poolCfg)
+		if err == nil {
+			return pool, nil
+		}
+
+		// เช่น docker compose ที่ depends_on แค่รอ container ขึ้น ไม่ได้รอ Postgres พร้อมรับ connection
+		wait := backoff/2 + rand.N(backoff/2+1)
+		if cfg.ConnectTimeout <= 0 || time.Now().Add(wait).After(deadline) {
+			return nil, fmt.Errorf("connect after %d attempts: %w", attempt, err)
+		}
+		log.Printf("database not ready (attempt %d): %v; retrying in %s", attempt, err, wait.Round(time.Millisecond))
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("connect: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func open(ctx context.Context, poolCfg *pgxpool.Config) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("pgxpool new: %w", err)
 	}
@@ -26,6 +74,37 @@ func Connect(ctx context.Context) (*pgxpool.Pool, error) {
 		pool.Close()
 		return nil, fmt.Errorf("pgxpool ping: %w", err)
 	}
-
 	return pool, nil
 }
+
+func poolConfig(cfg Config, url string) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	params := poolCfg.ConnConfig.RuntimeParams
+	if cfg.ApplicationName != "" {
+		params["application_name"] = cfg.ApplicationName
+	}
+	if cfg.StatementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	return poolCfg, nil
+}
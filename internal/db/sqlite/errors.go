@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"fristGoproject/internal/db"
+)
+
+// Translate แปลง error ของ SQLite ให้เป็น sentinel เดียวกับฝั่ง Postgres
+// (db.ErrUniqueViolation, db.ErrForeignKeyViolation, db.ErrSerialization) ค่าอื่นคืนตามเดิม
+func Translate(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	switch code := sqliteErr.Code(); {
+	case code == sqlite3.SQLITE_CONSTRAINT_UNIQUE, code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return fmt.Errorf("%w: %w", db.ErrUniqueViolation, err)
+	case code == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return fmt.Errorf("%w: %w", db.ErrForeignKeyViolation, err)
+	case code&0xff == sqlite3.SQLITE_BUSY:
+		// อีก connection ถือ write lock นานเกิน busy_timeout ลองใหม่ได้เหมือน serialization failure
+		return fmt.Errorf("%w: %w", db.ErrSerialization, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at TEXT NOT NULL,
+    action TEXT NOT NULL,
+    actor_type TEXT NOT NULL,
+    actor_id INTEGER,
+    target TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details TEXT CHECK (details IS NULL OR json_valid(details)),
+    pii_salt TEXT NOT NULL DEFAULT '',
+    pii_hash TEXT NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL UNIQUE,
+    erased_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor_id, id);
+CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target, id);
+CREATE INDEX IF NOT EXISTS audit_events_action_idx ON audit_events (action, id);
+
+-- audit log ต่อท้ายได้อย่างเดียว: ห้ามลบ และแก้ได้เฉพาะการล้างข้อมูลส่วนบุคคลครั้งเดียวตอนลบบัญชี
+CREATE TRIGGER IF NOT EXISTS audit_events_no_delete
+BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_events_erase_only
+BEFORE UPDATE ON audit_events
+WHEN OLD.erased_at IS NOT NULL OR NEW.erased_at IS NULL
+    OR NEW.ip <> '' OR NEW.user_agent <> '' OR NEW.details IS NOT NULL OR NEW.pii_salt <> ''
+    OR NEW.id IS NOT OLD.id OR NEW.occurred_at IS NOT OLD.occurred_at OR NEW.action IS NOT OLD.action
+    OR NEW.actor_type IS NOT OLD.actor_type OR NEW.actor_id IS NOT OLD.actor_id OR NEW.target IS NOT OLD.target
+    OR NEW.request_id IS NOT OLD.request_id OR NEW.pii_hash IS NOT OLD.pii_hash
+    OR NEW.prev_hash IS NOT OLD.prev_hash OR NEW.hash IS NOT OLD.hash
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only: only personal data may be erased');
+END;
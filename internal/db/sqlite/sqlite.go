@@ -0,0 +1,153 @@
+// Package sqlite เปิดฐานข้อมูล SQLite ไฟล์เดียวสำหรับการติดตั้งขนาดเล็กที่ไม่มี Postgres
+// ใช้ driver modernc.org/sqlite ที่เขียนด้วย Go ล้วน จึง build ด้วย CGO_ENABLED=0 ได้
+// schema แยกจากฝั่ง Postgres (อยู่ใน migrations ของ package นี้) และถูก migrate ทุกครั้งที่ Open
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"fristGoproject/internal/db/migrate"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// timeLayout เก็บเวลาเป็น UTC ความยาวคงที่ เรียงแบบข้อความได้ถูกต้องตามเวลา
+// และละเอียดระดับไมโครวินาทีเท่ากับ TIMESTAMPTZ ของ Postgres
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+// Open เปิด (หรือสร้าง) ไฟล์ฐานข้อมูลที่ path แล้วรัน migration ที่ยังไม่ได้รัน
+// เปิด foreign key, WAL และ busy timeout ทุก connection และ transaction จะจอง write lock ตั้งแต่ BEGIN
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create sqlite dir: %w", err)
+		}
+	}
+
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Set("_txlock", "immediate")
+	sqldb, err := sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	if err := sqldb.PingContext(ctx); err != nil {
+		sqldb.Close()
+		return nil, fmt.Errorf("ping sqlite: %w", err)
+	}
+
+	if _, err := Migrate(ctx, sqldb); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	return sqldb, nil
+}
+
+// Migrate รัน migration ที่ฝังมากับ binary ที่ยังไม่ได้รัน คืนรายการที่รันในครั้งนี้
+// ใช้รูปแบบไฟล์และการตรวจ checksum แบบเดียวกับ package migrate ของ Postgres
+func Migrate(ctx context.Context, sqldb *sql.DB) ([]migrate.Migration, error) {
+	sub, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := migrate.Load(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	const createTable = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TEXT NOT NULL
+		)
+	`
+	if _, err := sqldb.ExecContext(ctx, createTable); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	applied := map[int64]string{}
+	rows, err := sqldb.QueryContext(ctx, `SELECT version, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("query schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var (
+			version  int64
+			checksum string
+		)
+		if err := rows.Scan(&version, &checksum); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan schema_migrations: %w", err)
+		}
+		applied[version] = checksum
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate schema_migrations: %w", err)
+	}
+
+	var done []migrate.Migration
+	for _, mig := range migrations {
+		if checksum, ok := applied[mig.Version]; ok {
+			if checksum != mig.Checksum {
+				return done, fmt.Errorf("%w: %d_%s", migrate.ErrChecksumMismatch, mig.Version, mig.Name)
+			}
+			continue
+		}
+		err := withTx(ctx, sqldb, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+				mig.Version, mig.Name, mig.Checksum, FormatTime(time.Now()))
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("migrate up %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// FormatTime แปลงเวลาเป็นข้อความที่เก็บในคอลัมน์เวลา
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// ParseTime อ่านค่าที่ FormatTime เขียนไว้
+func ParseTime(s string) (time.Time, error) {
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse time %q: %w", s, err)
+	}
+	return t, nil
+}
+
+// ParseNullTime อ่านคอลัมน์เวลาที่เป็น NULL ได้ คืน nil เมื่อเป็น NULL
+func ParseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := ParseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"fristGoproject/internal/db"
+)
+
+// DBTX คือ method ที่ทั้ง *sql.DB และ *sql.Tx มีเหมือนกัน
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// TxManager เปิด transaction ของ SQLite แล้วฝากไว้ใน context (implement db.Transactor)
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager คืน TxManager ของฐาน sqldb
+func NewTxManager(sqldb *sql.DB) *TxManager {
+	return &TxManager{db: sqldb}
+}
+
+// WithinTx รัน fn ใน transaction ถ้า ctx มี transaction อยู่แล้วจะรันใน transaction เดิม
+// (SQLite ผ่าน database/sql ไม่มี savepoint ให้ ถ้า fn ที่ซ้อนอยู่คืน error ทั้งก้อนจะ rollback)
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	return withTx(ctx, m.db, func(tx *sql.Tx) error {
+		return fn(db.WithPrimary(context.WithValue(ctx, txKey{}, tx)))
+	})
+}
+
+// Conn คืน transaction ใน ctx ถ้ามี ไม่เช่นนั้นคืน fallback
+func Conn(ctx context.Context, fallback DBTX) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return fallback
+}
+
+// withTx เปิด transaction รัน fn แล้ว commit หรือ rollback (รวมถึงตอน panic)
+func withTx(ctx context.Context, sqldb *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := sqldb.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", Translate(err))
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", Translate(err))
+	}
+	return nil
+}
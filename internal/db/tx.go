@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX คือ method ที่ทั้ง *pgxpool.Pool และ pgx.Tx มีเหมือนกัน
+// repository เขียน query กับ DBTX จึงทำงานได้ทั้งนอกและใน transaction
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Transactor รันหลายคำสั่งของ repository ให้สำเร็จหรือล้มเหลวพร้อมกัน
+// fn ต้องใช้ ctx ที่ได้รับเท่านั้น เพราะ transaction ถูกส่งต่อผ่าน ctx นั้น
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NoTx รัน fn ตรง ๆ โดยไม่เปิด transaction ใช้กับ repository ที่ไม่ได้อยู่บน Postgres เช่น in-memory
+type NoTx struct{}
+
+func (NoTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type txKey struct{}
+
+// TxManager เปิด transaction บน primary แล้วฝากไว้ใน context ให้ repository หยิบไปใช้
+type TxManager struct {
+	pool *pgxpool.Pool
+}
+
+// NewTxManager คืน TxManager ที่เปิด transaction จาก pool (ควรเป็น primary)
+func NewTxManager(pool *pgxpool.Pool) *TxManager {
+	return &TxManager{pool: pool}
+}
+
+// WithinTx รัน fn ใน transaction ถ้า fn คืน error หรือ panic จะ rollback ไม่เช่นนั้นจะ commit
+// ถ้า ctx มี transaction อยู่แล้ว จะเปิดเป็น savepoint ซ้อนใน transaction เดิม
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	var parent DBTX = m.pool
+	if tx, ok := TxFromContext(ctx); ok {
+		parent = tx
+	}
+	return pgx.BeginFunc(ctx, parent, func(tx pgx.Tx) error {
+		return fn(WithPrimary(context.WithValue(ctx, txKey{}, tx)))
+	})
+}
+
+// TxFromContext คืน transaction ที่ WithinTx ฝากไว้ใน ctx (ถ้ามี)
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// Conn คืน transaction ใน ctx ถ้ามี ไม่เช่นนั้นคืน fallback (มักเป็น pool ของ repository)
+func Conn(ctx context.Context, fallback DBTX) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return fallback
+}
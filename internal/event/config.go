@@ -0,0 +1,109 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config คือค่าของ Dispatcher และรายการ sink อ่านจาก env ด้วย LoadConfig
+type Config struct {
+	Sinks        []Sink
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease คือเวลาที่ event ถูกจองไว้ระหว่างส่ง ต้องนานกว่าเวลาส่งหนึ่ง batch
+	// 0 หรือค่าที่สั้นกว่า batch × sink × 15s + 1m จะถูกปรับขึ้นใน NewDispatcher
+	Lease time.Duration
+	// Retention คือเวลาที่เก็บ event ที่ส่งแล้วไว้ก่อนลบ (0 = ไม่ลบ)
+	Retention time.Duration
+}
+
+func defaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+// LoadConfig อ่านค่าจาก env
+//
+//	EVENT_SINKS            รายชื่อ sink คั่นด้วย comma: log, webhook, nats (default log)
+//	EVENT_WEBHOOK_URL      (จำเป็นเมื่อใช้ webhook) URL ที่รับ POST
+//	NATS_URL               (จำเป็นเมื่อใช้ nats) เช่น nats://token@localhost:4222
+//	NATS_SUBJECT_PREFIX    prefix ของ subject (default ingoapi)
+//	OUTBOX_POLL_INTERVAL   ความถี่ตรวจ outbox (default 1s)
+//	OUTBOX_BATCH_SIZE      จำนวน event ต่อรอบ (default 100)
+//	OUTBOX_LEASE           เวลาที่จอง event ระหว่างส่ง (default และค่าต่ำสุดคือ batch × sink × 15s + 1m)
+//	OUTBOX_RETENTION       เก็บ event ที่ส่งแล้วนานเท่าไร (default 168h, 0 = ไม่ลบ)
+func LoadConfig() (Config, error) {
+	cfg := defaultConfig()
+	var errs []error
+
+	names := os.Getenv("EVENT_SINKS")
+	if names == "" {
+		names = "log"
+	}
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "":
+		case "log":
+			cfg.Sinks = append(cfg.Sinks, LogSink{})
+		case "webhook":
+			url := os.Getenv("EVENT_WEBHOOK_URL")
+			if url == "" {
+				errs = append(errs, errors.New("ต้องตั้ง EVENT_WEBHOOK_URL เมื่อใช้ sink webhook"))
+				continue
+			}
+			cfg.Sinks = append(cfg.Sinks, NewWebhookSink(url))
+		case "nats":
+			url := os.Getenv("NATS_URL")
+			if url == "" {
+				errs = append(errs, errors.New("ต้องตั้ง NATS_URL เมื่อใช้ sink nats"))
+				continue
+			}
+			prefix := os.Getenv("NATS_SUBJECT_PREFIX")
+			if prefix == "" {
+				prefix = "ingoapi"
+			}
+			sink, err := NewNATSSink(url, prefix)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			cfg.Sinks = append(cfg.Sinks, sink)
+		default:
+			errs = append(errs, fmt.Errorf("EVENT_SINKS รู้จักแค่ log, webhook, nats (ได้ %q)", name))
+		}
+	}
+
+	readDuration := func(key string, dst *time.Duration) {
+		if raw := os.Getenv(key); raw != "" {
+			v, err := time.ParseDuration(raw)
+			if err != nil || v < 0 {
+				errs = append(errs, fmt.Errorf("%s ต้องเป็นช่วงเวลา เช่น 30s หรือ 5m (ได้ %q)", key, raw))
+				return
+			}
+			*dst = v
+		}
+	}
+	readDuration("OUTBOX_POLL_INTERVAL", &cfg.PollInterval)
+	readDuration("OUTBOX_LEASE", &cfg.Lease)
+	readDuration("OUTBOX_RETENTION", &cfg.Retention)
+	if raw := os.Getenv("OUTBOX_BATCH_SIZE"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			errs = append(errs, fmt.Errorf("OUTBOX_BATCH_SIZE ต้องเป็นจำนวนเต็มบวก (ได้ %q)", raw))
+		} else {
+			cfg.BatchSize = v
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,134 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"fristGoproject/internal/db"
+)
+
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Hour
+	// sendTimeout จำกัดเวลาที่รอ sink หนึ่งตัวต่อ event
+	sendTimeout = 15 * time.Second
+)
+
+// Dispatcher อ่าน event ที่ถึงเวลาส่งจาก Store แล้วส่งให้ทุก Sink
+// event จะถูกทำเครื่องหมายว่าส่งแล้วเมื่อทุก sink รับได้ ถ้ามี sink ใดล้มเหลวจะลองส่งใหม่ให้ทุก sink
+// (sink ที่รับไปแล้วอาจได้ซ้ำ) โดยเว้นระยะแบบ exponential backoff สูงสุด 1 ชั่วโมง
+type Dispatcher struct {
+	store Store
+	sinks []Sink
+	cfg   Config
+}
+
+// NewDispatcher คืน dispatcher ที่ใช้ค่าจาก cfg (ค่าที่เป็นศูนย์ใช้ default ของ LoadConfig)
+func NewDispatcher(store Store, sinks []Sink, cfg Config) *Dispatcher {
+	def := defaultConfig()
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = def.PollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	// lease ต้องนานกว่าเวลาส่งทั้ง batch ในกรณีที่แย่ที่สุด ไม่อย่างนั้น instance อื่นจะจองซ้ำแล้วส่งซ้ำระหว่างที่ยังส่งอยู่
+	if need := batchLease(cfg.BatchSize, len(sinks)); cfg.Lease < need {
+		if cfg.Lease > 0 {
+			log.Printf("event dispatch: OUTBOX_LEASE %s is shorter than a worst-case batch, using %s", cfg.Lease, need)
+		}
+		cfg.Lease = need
+	}
+	return &Dispatcher{store: store, sinks: sinks, cfg: cfg}
+}
+
+// batchLease คือเวลาส่งหนึ่ง batch ในกรณีที่ทุก sink รอจนหมด sendTimeout บวกเผื่ออีกหนึ่งนาที
+func batchLease(batchSize, sinks int) time.Duration {
+	return time.Duration(batchSize*max(sinks, 1))*sendTimeout + time.Minute
+}
+
+// Run ส่ง event เป็นรอบ ๆ จนกว่า ctx จะถูกยกเลิก ถ้ารอบไหนได้ batch เต็มจะทำรอบถัดไปทันที
+func (d *Dispatcher) Run(ctx context.Context) {
+	ctx = db.WithPrimary(ctx)
+	lastPrune := time.Time{}
+	for {
+		n, err := d.DispatchOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("event dispatch: %v", err)
+		}
+
+		if d.cfg.Retention > 0 && time.Since(lastPrune) > time.Hour {
+			lastPrune = time.Now()
+			if pruned, err := d.store.Prune(ctx, d.cfg.Retention); err != nil {
+				log.Printf("event prune: %v", err)
+			} else if pruned > 0 {
+				log.Printf("event prune: removed %d delivered events", pruned)
+			}
+		}
+
+		if n == d.cfg.BatchSize && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.cfg.PollInterval):
+		}
+	}
+}
+
+// DispatchOnce จองและส่ง event หนึ่ง batch คืนจำนวน event ที่หยิบมา
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	events, err := d.store.Claim(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, e := range events {
+		if sendErr := d.deliver(ctx, e); sendErr != nil {
+			delay := retryDelay(e.Attempts)
+			log.Printf("event %d (%s) attempt %d failed, retry in %s: %v", e.ID, e.Type, e.Attempts, delay.Round(time.Second), sendErr)
+			if err := d.store.Retry(ctx, e.ID, delay, sendErr.Error()); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := d.store.MarkDelivered(ctx, e.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(events), errors.Join(errs...)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, e Event) error {
+	var failed []string
+	for _, sink := range d.sinks {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := sink.Send(sendCtx, e)
+		cancel()
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", sink.Name(), err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// retryDelay คืนระยะรอก่อนส่งครั้งถัดไป เพิ่มเป็นสองเท่าทุกครั้ง (มี jitter ±20%)
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxRetryDelay)
+	jitter := time.Duration(rand.Int64N(int64(delay)*2/5+1)) - delay/5
+	return delay + jitter
+}
@@ -0,0 +1,83 @@
+// Package event เก็บ domain event ของผู้ใช้ลงตาราง outbox ใน transaction เดียวกับการเปลี่ยนข้อมูล
+// แล้วให้ Dispatcher ส่งต่อไปยัง Sink (log, webhook, NATS) แบบ at-least-once
+//
+// ผู้รับต้องรับมือกับ event ซ้ำได้เอง (ใช้ ID เป็นตัวกันซ้ำ) และไม่ควรพึ่งลำดับของ event
+// เพราะ event ที่ส่งไม่ผ่านจะถูกลองใหม่ภายหลังโดยไม่รอ event ถัดไป
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Type คือชื่อ event ในรูป <aggregate>.<สิ่งที่เกิดขึ้น>
+type Type string
+
+const (
+	UserRegistered      Type = "user.registered"
+	UserLoggedIn        Type = "user.logged_in"
+	UserPasswordChanged Type = "user.password_changed"
+	UserProfileUpdated  Type = "user.profile_updated"
+	UserDeleted         Type = "user.deleted"
+)
+
+// Types คือ event ทั้งหมดที่ระบบส่งออก
+var Types = []Type{UserRegistered, UserLoggedIn, UserPasswordChanged, UserProfileUpdated, UserDeleted}
+
+// Event คือหนึ่งแถวใน outbox และเป็นรูป JSON ที่ส่งให้ sink
+// Data ตอนสร้างเป็นค่าใดก็ได้ที่ encode เป็น JSON ได้ ตอนอ่านจาก store จะเป็น json.RawMessage
+type Event struct {
+	ID         int64     `json:"id"`
+	Type       Type      `json:"type"`
+	UserID     int       `json:"user_id"`
+	Data       any       `json:"data"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Attempts คือจำนวนครั้งที่ dispatcher หยิบ event นี้ไปส่ง (รวมครั้งปัจจุบัน)
+	Attempts int `json:"-"`
+}
+
+// New สร้าง event ของผู้ใช้ userID ID และเวลาจะถูกเติมตอนบันทึกลง store
+func New(t Type, userID int, data any) Event {
+	if data == nil {
+		data = struct{}{}
+	}
+	return Event{Type: t, UserID: userID, Data: data}
+}
+
+// Publisher บันทึก event ลง outbox ถ้า ctx มี transaction จะเขียนใน transaction นั้น
+// event จึงถูกส่งออกก็ต่อเมื่อการเปลี่ยนข้อมูลที่คู่กัน commit สำเร็จ
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// Store คือ outbox ที่ Dispatcher ใช้หยิบ event ไปส่ง
+type Store interface {
+	Publisher
+	// Claim จอง event ที่ถึงเวลาส่งไม่เกิน limit รายการเป็นเวลา lease (instance อื่นจะไม่หยิบซ้ำระหว่างนั้น)
+	// ถ้า instance ที่จองไว้ตายไปก่อน event จะกลับมาให้หยิบใหม่เมื่อ lease หมด
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error)
+	// MarkDelivered บันทึกว่า event ส่งถึงทุก sink แล้ว
+	MarkDelivered(ctx context.Context, id int64) error
+	// Retry เลื่อนการส่งครั้งถัดไปออกไป delay และเก็บสาเหตุที่ส่งไม่สำเร็จ
+	Retry(ctx context.Context, id int64, delay time.Duration, cause string) error
+	// Prune ลบ event ที่ส่งแล้วนานกว่า age คืนจำนวนที่ลบ
+	Prune(ctx context.Context, age time.Duration) (int64, error)
+	// ListByUser คืน event ทั้งหมดของผู้ใช้ (ทั้งที่ส่งแล้วและยังรอส่ง) เรียงตาม id
+	ListByUser(ctx context.Context, userID int) ([]Event, error)
+	// Redact ล้าง data ของ event ของผู้ใช้เป็น {} คืนจำนวนแถวที่แก้
+	Redact(ctx context.Context, userID int) (int64, error)
+}
+
+func encodeData(e Event) ([]byte, error) {
+	data := e.Data
+	if data == nil {
+		data = struct{}{}
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s data: %w", e.Type, err)
+	}
+	return b, nil
+}
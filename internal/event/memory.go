@@ -0,0 +1,138 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore เก็บ outbox ไว้ในหน่วยความจำ ใช้คู่กับ DB_DRIVER=memory
+// ไม่มี transaction จึงบันทึก event ทันทีแม้ขั้นตอนถัดไปของผู้เรียกจะล้มเหลว
+type MemoryStore struct {
+	mu     sync.Mutex
+	events map[int64]*memoryEntry
+	nextID int64
+	now    func() time.Time
+}
+
+type memoryEntry struct {
+	event       Event
+	nextAttempt time.Time
+	lastError   string
+	deliveredAt time.Time
+}
+
+// NewMemoryStore คืน outbox ว่าง
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{events: map[int64]*memoryEntry{}, nextID: 1, now: time.Now}
+}
+
+func (m *MemoryStore) Publish(ctx context.Context, events ...Event) error {
+	encoded := make([][]byte, len(events))
+	for i, e := range events {
+		data, err := encodeData(e)
+		if err != nil {
+			return err
+		}
+		encoded[i] = data
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	for i, e := range events {
+		e.ID = m.nextID
+		m.nextID++
+		e.Data = json.RawMessage(encoded[i])
+		e.OccurredAt = now.UTC()
+		e.Attempts = 0
+		m.events[e.ID] = &memoryEntry{event: e, nextAttempt: now}
+	}
+	return nil
+}
+
+func (m *MemoryStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	var due []*memoryEntry
+	for _, entry := range m.events {
+		if entry.deliveredAt.IsZero() && !entry.nextAttempt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].event.ID < due[j].event.ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	events := make([]Event, 0, len(due))
+	for _, entry := range due {
+		entry.nextAttempt = now.Add(lease)
+		entry.event.Attempts++
+		events = append(events, entry.event)
+	}
+	return events, nil
+}
+
+func (m *MemoryStore) MarkDelivered(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, ok := m.events[id]; ok {
+		entry.deliveredAt = m.now()
+		entry.lastError = ""
+	}
+	return nil
+}
+
+func (m *MemoryStore) Retry(ctx context.Context, id int64, delay time.Duration, cause string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, ok := m.events[id]; ok {
+		entry.nextAttempt = m.now().Add(delay)
+		entry.lastError = cause
+	}
+	return nil
+}
+
+func (m *MemoryStore) Prune(ctx context.Context, age time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := m.now().Add(-age)
+	var n int64
+	for id, entry := range m.events {
+		if !entry.deliveredAt.IsZero() && entry.deliveredAt.Before(cutoff) {
+			delete(m.events, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) ListByUser(ctx context.Context, userID int) ([]Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var events []Event
+	for _, entry := range m.events {
+		if entry.event.UserID == userID {
+			events = append(events, entry.event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (m *MemoryStore) Redact(ctx context.Context, userID int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, entry := range m.events {
+		if data, _ := entry.event.Data.(json.RawMessage); entry.event.UserID == userID && string(data) != "{}" {
+			entry.event.Data = json.RawMessage("{}")
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,187 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSSink publish event ไปยังเซิร์ฟเวอร์ที่พูด NATS core protocol (NATS หรือตัวที่เข้ากันได้)
+// subject คือ <prefix>.<type> เช่น ingoapi.user.registered
+// หลัง PUB จะส่ง PING แล้วรอ PONG เพื่อให้แน่ใจว่าเซิร์ฟเวอร์รับข้อความแล้วจึงถือว่าส่งสำเร็จ
+// รองรับ user:pass หรือ token ใน URL (nats://token@host:4222) ยังไม่รองรับ TLS
+type NATSSink struct {
+	addr    string
+	user    string
+	pass    string
+	token   string
+	prefix  string
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewNATSSink แยก URL รูป nats://[user:pass@|token@]host[:port]
+func NewNATSSink(rawURL, subjectPrefix string) (*NATSSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("NATS_URL ไม่ถูกต้อง: %q", rawURL)
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("NATS_URL ต้องขึ้นต้นด้วย nats:// (ได้ %q)", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	s := &NATSSink{addr: addr, prefix: strings.TrimSuffix(subjectPrefix, "."), timeout: 10 * time.Second}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			s.user, s.pass = u.User.Username(), pass
+		} else {
+			s.token = u.User.Username()
+		}
+	}
+	return s, nil
+}
+
+func (s *NATSSink) Name() string { return "nats" }
+
+// Send ถ้า connection เดิมหลุด (เช่นเซิร์ฟเวอร์ตัดเพราะไม่ได้ตอบ PING ตอนว่าง) จะต่อใหม่แล้วลองอีกครั้ง
+func (s *NATSSink) Send(ctx context.Context, e Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	subject := string(e.Type)
+	if s.prefix != "" {
+		subject = s.prefix + "." + subject
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reused := s.conn != nil
+	err = s.publish(ctx, subject, payload)
+	if err != nil && reused {
+		err = s.publish(ctx, subject, payload)
+	}
+	return err
+}
+
+// Close ปิด connection ที่เปิดค้างไว้
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+	return nil
+}
+
+func (s *NATSSink) publish(ctx context.Context, subject string, payload []byte) error {
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+	}
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = s.conn.SetDeadline(deadline)
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		s.reset()
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	if err := s.awaitPong(); err != nil {
+		s.reset()
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	return nil
+}
+
+func (s *NATSSink) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: s.timeout}
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("nats connect: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(s.timeout))
+	s.conn, s.r = conn, bufio.NewReader(conn)
+
+	line, err := s.readLine()
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		s.reset()
+		return fmt.Errorf("nats connect: ไม่ได้รับ INFO จากเซิร์ฟเวอร์ (%q, %v)", line, err)
+	}
+
+	opts := map[string]any{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "ingoapi",
+		"lang":     "go",
+		"version":  "1",
+		"protocol": 0,
+	}
+	if s.token != "" {
+		opts["auth_token"] = s.token
+	}
+	if s.user != "" {
+		opts["user"], opts["pass"] = s.user, s.pass
+	}
+	connect, _ := json.Marshal(opts)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		s.reset()
+		return fmt.Errorf("nats connect: %w", err)
+	}
+	if err := s.awaitPong(); err != nil {
+		s.reset()
+		return fmt.Errorf("nats connect: %w", err)
+	}
+	return nil
+}
+
+// awaitPong อ่านจนเจอ PONG ตอบ PING ของเซิร์ฟเวอร์ และคืน error ถ้าได้ -ERR
+func (s *NATSSink) awaitPong() error {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK และ INFO ที่ส่งมาระหว่างทางไม่ต้องทำอะไร
+	}
+}
+
+func (s *NATSSink) readLine() (string, error) {
+	line, err := s.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (s *NATSSink) reset() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn, s.r = nil, nil
+}
@@ -0,0 +1,168 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"fristGoproject/internal/db"
+)
+
+// pgStore เป็น outbox บน Postgres ใช้เวลาของฐานข้อมูลทั้งหมด ไม่ขึ้นกับนาฬิกาของแต่ละ instance
+type pgStore struct {
+	db db.DBTX
+}
+
+// NewStore คืน outbox ที่ใช้ตาราง outbox ใน Postgres (ส่ง pool ของ primary)
+func NewStore(conn db.DBTX) Store {
+	return &pgStore{db: conn}
+}
+
+func (s *pgStore) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, s.db)
+}
+
+func (s *pgStore) Publish(ctx context.Context, events ...Event) error {
+	const query = `
+		INSERT INTO outbox (type, user_id, data)
+		VALUES ($1, $2, $3)
+	`
+
+	for _, e := range events {
+		data, err := encodeData(e)
+		if err != nil {
+			return err
+		}
+		if _, err := s.conn(ctx).Exec(ctx, query, e.Type, e.UserID, data); err != nil {
+			return fmt.Errorf("insert outbox: %w", db.Translate(err))
+		}
+	}
+	return nil
+}
+
+// Claim ใช้ FOR UPDATE SKIP LOCKED ให้หลาย instance หยิบงานพร้อมกันได้โดยไม่ชนกัน
+func (s *pgStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error) {
+	const query = `
+		UPDATE outbox
+		SET next_attempt_at = NOW() + make_interval(secs => $2), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE delivered_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, user_id, data, occurred_at, attempts
+	`
+
+	rows, err := s.conn(ctx).Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var (
+			e    Event
+			data []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.UserID, &data, &e.OccurredAt, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		e.Data = json.RawMessage(data)
+		events = append(events, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate outbox: %w", rows.Err())
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (s *pgStore) MarkDelivered(ctx context.Context, id int64) error {
+	const query = `
+		UPDATE outbox
+		SET delivered_at = NOW(), last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := s.conn(ctx).Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("mark outbox delivered: %w", err)
+	}
+	return nil
+}
+
+func (s *pgStore) Retry(ctx context.Context, id int64, delay time.Duration, cause string) error {
+	const query = `
+		UPDATE outbox
+		SET next_attempt_at = NOW() + make_interval(secs => $2), last_error = $3
+		WHERE id = $1
+	`
+
+	if _, err := s.conn(ctx).Exec(ctx, query, id, delay.Seconds(), cause); err != nil {
+		return fmt.Errorf("reschedule outbox: %w", err)
+	}
+	return nil
+}
+
+func (s *pgStore) Prune(ctx context.Context, age time.Duration) (int64, error) {
+	const query = `
+		DELETE FROM outbox
+		WHERE delivered_at < NOW() - make_interval(secs => $1)
+	`
+
+	tag, err := s.conn(ctx).Exec(ctx, query, age.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("prune outbox: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (s *pgStore) ListByUser(ctx context.Context, userID int) ([]Event, error) {
+	const query = `
+		SELECT id, type, user_id, data, occurred_at, attempts
+		FROM outbox
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := s.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var (
+			e    Event
+			data []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.UserID, &data, &e.OccurredAt, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		e.Data = json.RawMessage(data)
+		events = append(events, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate outbox: %w", rows.Err())
+	}
+	return events, nil
+}
+
+func (s *pgStore) Redact(ctx context.Context, userID int) (int64, error) {
+	const query = `
+		UPDATE outbox
+		SET data = '{}'::jsonb
+		WHERE user_id = $1 AND data <> '{}'::jsonb
+	`
+
+	tag, err := s.conn(ctx).Exec(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("redact outbox: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
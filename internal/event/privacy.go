@@ -0,0 +1,39 @@
+package event
+
+import (
+	"context"
+	"fmt"
+)
+
+// DataSource ให้ user.Service export และลบข้อมูลส่วนบุคคลใน outbox ได้ (implement user.DataSource)
+// data ของ event (เช่น email และชื่อใน user.registered) ถูกล้าง แต่ตัวแถวยังอยู่จนกว่า Prune จะลบ
+type DataSource struct {
+	store Store
+}
+
+// NewDataSource คืน DataSource ของ outbox store
+func NewDataSource(store Store) *DataSource {
+	return &DataSource{store: store}
+}
+
+// Name ใช้เป็นชื่อ section ใน user export
+func (d *DataSource) Name() string {
+	return "events"
+}
+
+// Export คืน event ทั้งหมดของผู้ใช้ที่ยังอยู่ใน outbox
+func (d *DataSource) Export(ctx context.Context, userID int) (any, error) {
+	events, err := d.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ดึง event ของผู้ใช้: %w", err)
+	}
+	return events, nil
+}
+
+// Erase ล้าง data ของทุก event ของผู้ใช้ event ที่ยังไม่ได้ส่งจะถูกส่งออกไปโดยไม่มีข้อมูลส่วนบุคคล
+func (d *DataSource) Erase(ctx context.Context, userID int) error {
+	if _, err := d.store.Redact(ctx, userID); err != nil {
+		return fmt.Errorf("ล้างข้อมูลใน outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Sink คือปลายทางที่ Dispatcher ส่ง event ไปให้ คืน error เมื่ออยากให้ลองส่งใหม่ภายหลัง
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, e Event) error
+}
+
+// LogSink เขียน event ลง log (เหมาะกับ dev)
+type LogSink struct{}
+
+func (LogSink) Name() string { return "log" }
+
+func (LogSink) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	log.Printf("event %s", body)
+	return nil
+}
+
+// WebhookSink POST event เป็น JSON ไปที่ URL เดียว ถือว่าสำเร็จเมื่อได้ status 2xx
+// header X-Event-ID ใช้กันรับซ้ำฝั่งผู้รับ
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookSink คืน sink ที่ใช้ http.Client ของตัวเองพร้อม timeout
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ingoapi-events")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(e.ID, 10))
+	req.Header.Set("X-Event-Type", string(e.Type))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"fristGoproject/internal/db/sqlite"
+)
+
+// sqliteStore เป็น outbox บน SQLite ใช้ฐานเดียวกับ repository อื่นจาก sqlite.Open
+type sqliteStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteStore คืน outbox ที่ใช้ตาราง outbox ในฐาน SQLite
+func NewSQLiteStore(sqldb *sql.DB) Store {
+	return &sqliteStore{db: sqldb, now: time.Now}
+}
+
+func (s *sqliteStore) conn(ctx context.Context) sqlite.DBTX {
+	return sqlite.Conn(ctx, s.db)
+}
+
+func (s *sqliteStore) Publish(ctx context.Context, events ...Event) error {
+	const query = `
+		INSERT INTO outbox (type, user_id, data, occurred_at, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	now := sqlite.FormatTime(s.now())
+	for _, e := range events {
+		data, err := encodeData(e)
+		if err != nil {
+			return err
+		}
+		if _, err := s.conn(ctx).ExecContext(ctx, query, e.Type, e.UserID, string(data), now, now); err != nil {
+			return fmt.Errorf("insert outbox: %w", sqlite.Translate(err))
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error) {
+	const query = `
+		UPDATE outbox
+		SET next_attempt_at = ?, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE delivered_at IS NULL AND next_attempt_at <= ?
+			ORDER BY id
+			LIMIT ?
+		)
+		RETURNING id, type, user_id, data, occurred_at, attempts
+	`
+
+	now := s.now()
+	rows, err := s.conn(ctx).QueryContext(ctx, query, sqlite.FormatTime(now.Add(lease)), sqlite.FormatTime(now), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox: %w", sqlite.Translate(err))
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var (
+			e          Event
+			data       string
+			occurredAt string
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.UserID, &data, &occurredAt, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		if e.OccurredAt, err = sqlite.ParseTime(occurredAt); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		e.Data = json.RawMessage(data)
+		events = append(events, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate outbox: %w", rows.Err())
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (s *sqliteStore) MarkDelivered(ctx context.Context, id int64) error {
+	const query = `UPDATE outbox SET delivered_at = ?, last_error = NULL WHERE id = ?`
+
+	if _, err := s.conn(ctx).ExecContext(ctx, query, sqlite.FormatTime(s.now()), id); err != nil {
+		return fmt.Errorf("mark outbox delivered: %w", sqlite.Translate(err))
+	}
+	return nil
+}
+
+func (s *sqliteStore) Retry(ctx context.Context, id int64, delay time.Duration, cause string) error {
+	const query = `UPDATE outbox SET next_attempt_at = ?, last_error = ? WHERE id = ?`
+
+	if _, err := s.conn(ctx).ExecContext(ctx, query, sqlite.FormatTime(s.now().Add(delay)), cause, id); err != nil {
+		return fmt.Errorf("reschedule outbox: %w", sqlite.Translate(err))
+	}
+	return nil
+}
+
+func (s *sqliteStore) Prune(ctx context.Context, age time.Duration) (int64, error) {
+	const query = `DELETE FROM outbox WHERE delivered_at < ?`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, sqlite.FormatTime(s.now().Add(-age)))
+	if err != nil {
+		return 0, fmt.Errorf("prune outbox: %w", sqlite.Translate(err))
+	}
+	return res.RowsAffected()
+}
+
+func (s *sqliteStore) ListByUser(ctx context.Context, userID int) ([]Event, error) {
+	const query = `
+		SELECT id, type, user_id, data, occurred_at, attempts
+		FROM outbox
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list outbox: %w", sqlite.Translate(err))
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var (
+			e          Event
+			data       string
+			occurredAt string
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.UserID, &data, &occurredAt, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		if e.OccurredAt, err = sqlite.ParseTime(occurredAt); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		e.Data = json.RawMessage(data)
+		events = append(events, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate outbox: %w", rows.Err())
+	}
+	return events, nil
+}
+
+func (s *sqliteStore) Redact(ctx context.Context, userID int) (int64, error) {
+	const query = `UPDATE outbox SET data = '{}' WHERE user_id = ? AND data <> '{}'`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("redact outbox: %w", sqlite.Translate(err))
+	}
+	return res.RowsAffected()
+}
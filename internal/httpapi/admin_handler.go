@@ -0,0 +1,172 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fristGoproject/internal/httpapi/dto"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/user"
+)
+
+// maxImportSize จำกัดขนาดไฟล์นำเข้าผ่าน HTTP (ไฟล์ใหญ่กว่านี้ให้ใช้ userctl import)
+const maxImportSize = 64 << 20
+
+const (
+	// importTimeout แทน ReadTimeout/WriteTimeout ของเซิร์ฟเวอร์ระหว่างนำเข้า (นานกว่านี้ให้ใช้ userctl import)
+	importTimeout = 30 * time.Minute
+	// exportIdleTimeout คือเวลาที่ export ต้องส่งข้อมูลก้อนถัดไปให้ทัน deadline ถูกเลื่อนทุกครั้งที่ flush
+	exportIdleTimeout = time.Minute
+	// exportFlushBytes ส่งข้อมูลที่ค้างใน buffer ออกทุก ๆ เท่านี้ไบต์
+	exportFlushBytes = 32 << 10
+)
+
+// AdminHandler รวม endpoint สำหรับผู้ดูแลระบบ
+type AdminHandler struct {
+	users *user.Service
+}
+
+// NewAdminHandler คืน handler ที่เชื่อมกับ user service เรียบร้อยแล้ว
+func NewAdminHandler(users *user.Service) *AdminHandler {
+	return &AdminHandler{users: users}
+}
+
+// ImportUsers นำเข้าผู้ใช้จาก body (CSV หรือ JSON Lines) แล้วคืนสรุปผลรายแถว
+// query: format=csv|jsonl, dry_run=true, on_duplicate=skip|update|fail
+func (h *AdminHandler) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = formatFromContentType(r.Header.Get("Content-Type"))
+	}
+	dryRun, _ := strconv.ParseBool(q.Get("dry_run"))
+	policy, err := user.ParseDuplicatePolicy(q.Get("on_duplicate"))
+	if err != nil {
+		writeInvalid(w, r, codeInvalidParameter, fieldError(r, "on_duplicate", i18n.MsgFieldOneOf, "skip, update, fail"))
+		return
+	}
+
+	if format != "csv" && format != "jsonl" && format != "ndjson" {
+		writeInvalid(w, r, codeInvalidParameter, fieldError(r, "format", i18n.MsgFieldOneOf, "csv, jsonl"))
+		return
+	}
+
+	// ReadTimeout/WriteTimeout 10 วินาทีของเซิร์ฟเวอร์ไม่พอสำหรับไฟล์จริง ขยายเฉพาะคำขอนี้
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	if err := rc.SetReadDeadline(deadline); err != nil {
+		log.Printf("import users: extend read deadline: %v", err)
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil {
+		log.Printf("import users: extend write deadline: %v", err)
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	src, err := user.NewRowReader(format, body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, i18n.MsgTooLarge)
+			return
+		}
+		// header ของ CSV ผิด importer คืน *i18n.Error จึงแปลเป็นภาษาของคำขอได้
+		message := err.Error()
+		var detail *i18n.Error
+		if errors.As(err, &detail) {
+			message = detail.Localize(i18n.FromContext(r.Context()))
+		}
+		writeInvalid(w, r, codeValidationFailed, dto.FieldError{Field: "body", Code: "csv_header", Message: message})
+		return
+	}
+
+	result, err := h.users.Import(r.Context(), src, user.ImportOptions{DryRun: dryRun, OnDuplicate: policy})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, i18n.MsgTooLarge)
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	writeJSON(w, status, result)
+}
+
+// ExportUsers ส่งผู้ใช้ทั้งหมดออกเป็น CSV หรือ JSON Lines แบบ streaming
+// query: format=csv|jsonl, include_password_hash=true (ใช้ตอนย้ายระบบเท่านั้น)
+func (h *AdminHandler) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = "jsonl"
+	}
+	includeHash, _ := strconv.ParseBool(q.Get("include_password_hash"))
+
+	contentType := "application/x-ndjson"
+	if format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().UTC().Format("20060102T150405Z"), format))
+
+	out := &flushWriter{w: w, rc: http.NewResponseController(w)}
+	out.extendDeadline()
+	dst, err := user.NewRowWriter(format, out, includeHash)
+	if err != nil {
+		writeInvalid(w, r, codeInvalidParameter, fieldError(r, "format", i18n.MsgFieldOneOf, "csv, jsonl"))
+		return
+	}
+
+	// header ถูกส่งไปแล้วตอนเขียนแถวแรก ถ้าพังกลางทางทำได้แค่ log ไว้ (client จะได้ไฟล์ไม่ครบ)
+	if err := h.users.ExportAll(r.Context(), dst); err != nil {
+		log.Printf("export users: %v", err)
+	}
+}
+
+// flushWriter ส่งข้อมูล export ออกไปทีละก้อนแทนการค้างใน buffer จนจบ
+// และเลื่อน write deadline ทุกครั้งที่ flush ไฟล์ใหญ่แค่ไหนก็ไม่โดนตัดตราบที่ยังส่งข้อมูลได้
+type flushWriter struct {
+	w       io.Writer
+	rc      *http.ResponseController
+	pending int
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.pending += n
+	if err == nil && f.pending >= exportFlushBytes {
+		f.pending = 0
+		f.extendDeadline()
+		err = f.rc.Flush()
+	}
+	return n, err
+}
+
+func (f *flushWriter) extendDeadline() {
+	if err := f.rc.SetWriteDeadline(time.Now().Add(exportIdleTimeout)); err != nil {
+		log.Printf("export users: extend write deadline: %v", err)
+	}
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return "csv"
+	case "application/x-ndjson", "application/jsonl", "application/jsonlines":
+		return "jsonl"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"fristGoproject/internal/audit"
+	"fristGoproject/internal/i18n"
+)
+
+// AuditHandler เปิดให้ admin ค้นหา audit log
+type AuditHandler struct {
+	service *audit.Service
+}
+
+// NewAuditHandler คืน handler ที่เชื่อมกับ audit service เรียบร้อยแล้ว
+func NewAuditHandler(service *audit.Service) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// List คืน audit log ล่าสุดก่อน
+// query: action, actor_id, target (เช่น user:12), user_id (เป็นผู้กระทำหรือเป้าหมาย), request_id,
+// since, until (RFC 3339), before_id (แบ่งหน้าด้วย id ของรายการสุดท้ายที่ได้), limit (default 50 สูงสุด 500)
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := audit.Filter{
+		Action:    audit.Action(q.Get("action")),
+		Target:    q.Get("target"),
+		RequestID: q.Get("request_id"),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"actor_id", &filter.ActorID},
+		{"user_id", &filter.UserID},
+		{"limit", &filter.Limit},
+	} {
+		if raw := q.Get(p.name); raw != "" {
+			v, err := strconv.Atoi(raw)
+			if err != nil || v <= 0 {
+				writeInvalid(w, r, codeInvalidParameter, fieldError(r, p.name, i18n.MsgFieldPositiveInt))
+				return
+			}
+			*p.dst = v
+		}
+	}
+	if raw := q.Get("before_id"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v <= 0 {
+			writeInvalid(w, r, codeInvalidParameter, fieldError(r, "before_id", i18n.MsgFieldPositiveInt))
+			return
+		}
+		filter.BeforeID = v
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"since", &filter.Since},
+		{"until", &filter.Until},
+	} {
+		if raw := q.Get(p.name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				writeInvalid(w, r, codeInvalidParameter, fieldError(r, p.name, i18n.MsgFieldRFC3339))
+				return
+			}
+			*p.dst = t
+		}
+	}
+
+	entries, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
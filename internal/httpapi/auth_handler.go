@@ -3,12 +3,12 @@ package httpapi
 import (
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"strings"
 
 	"fristGoproject/internal/auth"
 	"fristGoproject/internal/httpapi/dto"
+	"fristGoproject/internal/i18n"
 )
 
 // AuthHandler รับผิดชอบจัดการเส้นทางที่เกี่ยวกับการยืนยันตัวตนทั้งหมด
@@ -23,37 +23,17 @@ func NewAuthHandler(service *auth.Service) *AuthHandler {
 
 // Register รับคำขอสมัครสมาชิกใหม่และอ่านข้อมูลจาก JSON
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "ไม่อนุญาตให้ใช้เมธอดนี้", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var body dto.RegisterRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "เนื้อหาไม่ใช่ JSON ที่ถูกต้อง", http.StatusBadRequest)
+	if !decodeJSON(w, r, &body) {
 		return
 	}
 
-	email := strings.TrimSpace(body.Email)
-	name := strings.TrimSpace(body.Name)
-	if email == "" || name == "" {
-		http.Error(w, "email และ name ต้องไม่ว่าง", http.StatusBadRequest)
-		return
-	}
-	passwordHex, ok := normalizeSHA256Hex(body.Password)
-	if !ok {
-		http.Error(w, "password ต้องเป็น SHA-256 hex 64 ตัวอักษร", http.StatusBadRequest)
-		return
-	}
-
-	u, err := h.service.Register(r.Context(), email, passwordHex, name)
+	// รูปแบบถูกตรวจแล้วด้วยกฎ sha256hex เหลือแค่ตัด 0x และแปลงเป็นตัวพิมพ์เล็ก
+	passwordHex, _ := normalizeSHA256Hex(body.Password)
+	u, err := h.service.Register(r.Context(), body.Email, passwordHex, body.Name)
 	if err != nil {
-		status := http.StatusBadRequest
-		if errors.Is(err, auth.ErrEmailInUse) {
-			status = http.StatusConflict
-		}
-		http.Error(w, err.Error(), status)
+		writeError(w, r, err)
 		return
 	}
 
@@ -62,36 +42,16 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 // Login ตรวจสอบอีเมลและรหัสผ่านจากคำขอ
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "ไม่อนุญาตให้ใช้เมธอดนี้", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var body dto.LoginRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "เนื้อหาไม่ใช่ JSON ที่ถูกต้อง", http.StatusBadRequest)
-		return
-	}
-
-	email := strings.TrimSpace(body.Email)
-	if email == "" {
-		http.Error(w, "email ต้องไม่ว่าง", http.StatusBadRequest)
-		return
-	}
-	passwordHex, ok := normalizeSHA256Hex(body.Password)
-	if !ok {
-		http.Error(w, "password ต้องเป็น SHA-256 hex 64 ตัวอักษร", http.StatusBadRequest)
+	if !decodeJSON(w, r, &body) {
 		return
 	}
 
-	u, err := h.service.Login(r.Context(), email, passwordHex)
+	passwordHex, _ := normalizeSHA256Hex(body.Password)
+	u, err := h.service.Login(r.Context(), body.Email, passwordHex)
 	if err != nil {
-		status := http.StatusBadRequest
-		if errors.Is(err, auth.ErrInvalidCredentials) {
-			status = http.StatusUnauthorized
-		}
-		http.Error(w, err.Error(), status)
+		writeError(w, r, err)
 		return
 	}
 
@@ -100,45 +60,21 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 // ChangePassword ตรวจสอบรหัสเดิมก่อนเปลี่ยนไปเป็นรหัสใหม่
 func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "ไม่อนุญาตให้ใช้เมธอดนี้", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var body dto.ChangePasswordRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "เนื้อหาไม่ใช่ JSON ที่ถูกต้อง", http.StatusBadRequest)
-		return
-	}
-
-	email := strings.TrimSpace(body.Email)
-	if email == "" {
-		http.Error(w, "email ต้องไม่ว่าง", http.StatusBadRequest)
-		return
-	}
-	oldPassword, ok := normalizeSHA256Hex(body.OldPassword)
-	if !ok {
-		http.Error(w, "old_password ต้องเป็น SHA-256 hex 64 ตัวอักษร", http.StatusBadRequest)
-		return
-	}
-	newPassword, ok := normalizeSHA256Hex(body.NewPassword)
-	if !ok {
-		http.Error(w, "new_password ต้องเป็น SHA-256 hex 64 ตัวอักษร", http.StatusBadRequest)
+	if !decodeJSON(w, r, &body) {
 		return
 	}
 
-	if err := h.service.ChangePassword(r.Context(), email, oldPassword, newPassword); err != nil {
-		status := http.StatusBadRequest
-		if errors.Is(err, auth.ErrInvalidCredentials) {
-			status = http.StatusUnauthorized
-		}
-		http.Error(w, err.Error(), status)
+	oldPassword, _ := normalizeSHA256Hex(body.OldPassword)
+	newPassword, _ := normalizeSHA256Hex(body.NewPassword)
+	if err := h.service.ChangePassword(r.Context(), body.Email, oldPassword, newPassword); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]string{
-		"message": "เปลี่ยนรหัสผ่านเรียบร้อย",
+		"message": i18n.T(i18n.FromContext(r.Context()), i18n.MsgPasswordChanged),
 	})
 }
 
@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"fristGoproject/internal/avatar"
+	"fristGoproject/internal/i18n"
+)
+
+// multipartOverhead เผื่อขนาดของ boundary/header ใน multipart นอกเหนือจากตัวไฟล์
+const multipartOverhead = 64 << 10
+
+// AvatarHandler จัดการอัปโหลด/ลบรูปโปรไฟล์ของผู้ใช้ที่ล็อกอินอยู่
+type AvatarHandler struct {
+	service *avatar.Service
+}
+
+// NewAvatarHandler คืน handler ที่เชื่อมกับ avatar service เรียบร้อยแล้ว
+func NewAvatarHandler(service *avatar.Service) *AvatarHandler {
+	return &AvatarHandler{service: service}
+}
+
+// Upload รับรูปโปรไฟล์แบบ multipart/form-data (field ชื่อ "avatar")
+func (h *AvatarHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, avatar.MaxUploadSize+multipartOverhead)
+	file, header, err := r.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, i18n.MsgTooLarge)
+			return
+		}
+		writeInvalid(w, r, codeValidationFailed, fieldError(r, "avatar", i18n.MsgFieldFile))
+		return
+	}
+	defer file.Close()
+
+	if declared := header.Header.Get("Content-Type"); declared != "" && !strings.HasPrefix(declared, "image/") {
+		writeError(w, r, avatar.ErrUnsupportedType)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, avatar.MaxUploadSize+1))
+	if err != nil {
+		writeInvalid(w, r, codeValidationFailed, fieldError(r, "avatar", i18n.MsgFieldUnreadable))
+		return
+	}
+	if len(data) > avatar.MaxUploadSize {
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, i18n.MsgTooLarge)
+		return
+	}
+
+	updated, err := h.service.Upload(r.Context(), u.ID, data)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// Remove ลบรูปโปรไฟล์ของผู้ใช้ที่ล็อกอินอยู่
+func (h *AvatarHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+
+	if err := h.service.Remove(r.Context(), u.ID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
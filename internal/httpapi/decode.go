@@ -0,0 +1,208 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"fristGoproject/internal/httpapi/dto"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/validate"
+)
+
+// ขนาด body สูงสุดของแต่ละกลุ่มเส้นทาง ส่งให้ limitJSON ตอนลงทะเบียนใน router
+// login จำกัดแน่นที่สุดเพราะทุกคำขอเสีย Argon2 หนึ่งรอบ
+const (
+	maxLoginBody   = 1 << 10
+	maxSmallBody   = 16 << 10
+	maxProfileBody = 64 << 10
+	// maxJSONBody ใช้เมื่อเส้นทางไม่ได้ห่อด้วย limitJSON
+	maxJSONBody = 1 << 20
+)
+
+// limitJSON ตรวจ Content-Type และขนาด body ก่อนถึง handler (และก่อน authn ที่ต้องเสีย Argon2)
+// Content-Type ต้องเป็น application/json หรือ */*+json ได้ 415 ถ้าไม่ใช่ body ใหญ่เกิน limit ได้ 413
+// คำขอที่ไม่มี body ผ่านได้โดยไม่ต้องมี Content-Type (เช่นรับคำเชิญด้วย ?token=)
+func limitJSON(limit int64, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength == 0 {
+			next(w, r)
+			return
+		}
+		if !isJSONContentType(r.Header.Get("Content-Type")) {
+			writeProblem(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMedia, i18n.MsgUnsupportedMedia)
+			return
+		}
+		if r.ContentLength > limit {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, i18n.MsgTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next(w, r)
+	}
+}
+
+// isJSONContentType รับ application/json และ media type ที่ลงท้าย +json (เช่น application/merge-patch+json)
+func isJSONContentType(header string) bool {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// decodeJSON อ่าน body เป็น JSON object ลงใน dst (pointer ไปยัง struct ใน dto) แล้วตรวจตาม tag validate
+// body ที่ไม่ใช่ JSON object เดียว (รวมถึงมีข้อมูลต่อท้าย) ได้ 400 ใหญ่เกิน limit ของเส้นทางได้ 413
+// key ซ้ำ field ที่ไม่รู้จัก ชนิดข้อมูลผิด และกฎที่ไม่ผ่าน ถูกรวมเป็น 422 ครั้งเดียวพร้อมรายการ errors ทุก field
+// คืน false เมื่อเขียน error response ไปแล้ว handler ต้อง return ทันที
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxJSONBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, i18n.MsgTooLarge)
+			return false
+		}
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, i18n.MsgInvalidJSON)
+		return false
+	}
+	duplicates, err := duplicateKeys(data)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, i18n.MsgInvalidJSON)
+		return false
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || raw == nil {
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, i18n.MsgInvalidJSON)
+		return false
+	}
+
+	rv := reflect.ValueOf(dst).Elem()
+	rt := rv.Type()
+	var invalid []dto.FieldError
+	// encoding/json เก็บค่าสุดท้ายของ key ซ้ำเงียบ ๆ ซึ่งทำให้ proxy กับเซิร์ฟเวอร์เห็น body คนละแบบได้
+	for _, path := range duplicates {
+		invalid = append(invalid, fieldError(r, path, i18n.MsgFieldDuplicate))
+	}
+	badType := map[string]bool{}
+	for i := range rt.NumField() {
+		sf := rt.Field(i)
+		name := validate.FieldName(sf)
+		value, ok := raw[name]
+		if !ok || !sf.IsExported() {
+			continue
+		}
+		delete(raw, name)
+		// แยกถอดทีละ field เพื่อให้รายงานชนิดผิดได้ครบทุก field (json.Unmarshal คืนแค่ error แรก)
+		if err := json.Unmarshal(value, rv.Field(i).Addr().Interface()); err != nil {
+			badType[name] = true
+			invalid = append(invalid, fieldError(r, name, i18n.MsgFieldType, jsonTypeName(sf.Type)))
+		}
+	}
+
+	// key ที่เหลือคือ field ที่ไม่รู้จัก (ชื่อต้องตรงตัวพิมพ์ ไม่ยอมแบบไม่สนตัวพิมพ์ของ encoding/json)
+	unknown := make([]string, 0, len(raw))
+	for key := range raw {
+		unknown = append(unknown, key)
+	}
+	slices.Sort(unknown)
+	for _, key := range unknown {
+		invalid = append(invalid, fieldError(r, key, i18n.MsgFieldUnknown))
+	}
+
+	for _, v := range validate.Struct(dst) {
+		if badType[v.Field] {
+			continue
+		}
+		invalid = append(invalid, fieldError(r, v.Field, v.Message, v.Args...))
+	}
+
+	if len(invalid) > 0 {
+		writeInvalid(w, r, codeValidationFailed, invalid...)
+		return false
+	}
+	return true
+}
+
+// duplicateKeys อ่าน data ทีละ token แล้วคืน path (คั่นด้วย .) ของ key ที่ซ้ำกันใน object เดียวกันทุกระดับ
+// คืน error ถ้า data ไม่ใช่ JSON value เดียว (ไวยากรณ์ผิดหรือมีข้อมูลต่อท้าย)
+func duplicateKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var duplicates []string
+	if err := walkJSON(dec, "", &duplicates); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("trailing data after JSON value")
+	}
+	return duplicates, nil
+}
+
+func walkJSON(dec *json.Decoder, path string, duplicates *[]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		seen := map[string]bool{}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			if seen[key] {
+				*duplicates = append(*duplicates, child)
+			}
+			seen[key] = true
+			if err := walkJSON(dec, child, duplicates); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for dec.More() {
+			if err := walkJSON(dec, path, duplicates); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	// ปิด object หรือ array
+	_, err = dec.Token()
+	return err
+}
+
+// jsonTypeName คืนชื่อชนิดตามศัพท์ของ JSON สำหรับข้อความ error
+func jsonTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
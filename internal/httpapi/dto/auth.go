@@ -2,20 +2,20 @@ package dto
 
 // RegisterRequest represents the expected payload for creating a new account.
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,sha256hex"`
+	Name     string `json:"name" validate:"required,name,max=100"`
 }
 
 // LoginRequest represents the payload required to authenticate a user.
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required,sha256hex"`
 }
 
 // ChangePasswordRequest holds the data required when updating a password.
 type ChangePasswordRequest struct {
-	Email       string `json:"email"`
-	OldPassword string `json:"old_password"`
-	NewPassword string `json:"new_password"`
+	Email       string `json:"email" validate:"required"`
+	OldPassword string `json:"old_password" validate:"required,sha256hex"`
+	NewPassword string `json:"new_password" validate:"required,sha256hex"`
 }
@@ -0,0 +1,24 @@
+package dto
+
+// CreateOrganizationRequest represents the payload for creating a tenant.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,printable,max=100"`
+	Slug string `json:"slug" validate:"required"`
+}
+
+// InviteMemberRequest holds the invitee email and the role they will receive.
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"oneof=owner admin member"`
+}
+
+// ChangeRoleRequest updates the role of an existing member in the current tenant.
+type ChangeRoleRequest struct {
+	UserID int    `json:"user_id" validate:"required,min=1"`
+	Role   string `json:"role" validate:"required,oneof=owner admin member"`
+}
+
+// AcceptInvitationRequest carries the token from the invitation email.
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"max=256"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+// Problem is an RFC 9457 problem details body (application/problem+json).
+// Code is a stable machine-readable identifier such as "auth.invalid_credentials";
+// clients should branch on Code rather than on Detail, which is localized and may change.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes one invalid field of a request.
+// Field is the JSON field name (or query parameter/header), with a JSON Pointer suffix for nested values.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
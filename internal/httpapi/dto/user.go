@@ -0,0 +1,8 @@
+package dto
+
+// UpdateProfileRequest represents a partial update of the caller's profile.
+// Attributes follows JSON Merge Patch semantics: a null value removes the key.
+type UpdateProfileRequest struct {
+	Name       *string        `json:"name" validate:"required,name,max=100"`
+	Attributes map[string]any `json:"attributes"`
+}
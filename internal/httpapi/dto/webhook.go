@@ -0,0 +1,13 @@
+package dto
+
+// CreateWebhookRequest registers an endpoint for the given event types ("*" for all).
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Events      []string `json:"events" validate:"required"`
+	Description string   `json:"description" validate:"max=500"`
+}
+
+// ReplayWebhookRequest asks for an existing delivery to be sent again.
+type ReplayWebhookRequest struct {
+	DeliveryID int64 `json:"delivery_id" validate:"required,min=1"`
+}
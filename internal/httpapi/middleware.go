@@ -1,6 +1,23 @@
 package httpapi
 
-import "net/http"
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"fristGoproject/internal/audit"
+	"fristGoproject/internal/auth"
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/user"
+)
 
 // corsMiddleware ตั้งค่า CORS header ให้ทุกคำขอและตอบกลับ preflight
 func corsMiddleware(next http.Handler) http.Handler {
@@ -9,6 +26,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "*")
 		w.Header().Set("Access-Control-Max-Age", "600")
+		w.Header().Set("Access-Control-Expose-Headers", requestIDHeader+", Deprecation, Sunset, Link")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
@@ -18,3 +36,185 @@ func corsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// primaryForWrites ตรึงคำขอที่เปลี่ยนข้อมูล (POST, PUT, PATCH, DELETE) ไว้กับ primary
+// การอ่านหลังเขียนภายในคำขอเดียวกันจึงเห็นข้อมูลที่เพิ่งเขียนเสมอ ไม่ขึ้นกับ lag ของ replica
+func primaryForWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		default:
+			r = r.WithContext(db.WithPrimary(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestIDHeader คือ header ที่ใช้ส่งต่อ request ID ระหว่าง client, proxy และ log
+const requestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestContext แนบ request ID, IP และ user agent ไว้ใน context ให้ audit log
+// ใช้ X-Request-ID ที่ client หรือ proxy ส่งมาถ้ารูปแบบถูกต้อง ไม่เช่นนั้นสร้างใหม่ และส่งกลับใน response เสมอ
+// IP มาจาก RemoteAddr ถ้าอยู่หลัง reverse proxy จะเห็นเป็น IP ของ proxy
+func requestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		ctx := audit.WithRequest(r.Context(), audit.RequestInfo{ID: id, IP: ip, UserAgent: r.UserAgent()})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// negotiateLanguage เลือกภาษาของ response จาก Accept-Language แล้วแนบไว้ใน context ให้ handler ใช้ผ่าน i18n.FromContext
+// code ใน error response เหมือนกันทุกภาษา เปลี่ยนแค่ detail และ message
+func negotiateLanguage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", string(lang))
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.WithLang(r.Context(), lang)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// statusWriter จำ status code ที่ handler ตอบไว้ให้ middleware ใช้หลัง handler ทำงานจบ
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap ให้ http.ResponseController เข้าถึง writer ตัวจริงได้ (เช่น Flush ตอน streaming)
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type contextKey int
+
+const (
+	currentUserKey contextKey = iota
+	currentTenantKey
+)
+
+// Authenticator ตรวจสอบตัวตนผู้เรียกสำหรับ endpoint ที่ต้องล็อกอิน
+// ผู้ใช้ทั่วไปใช้ HTTP Basic auth โดย password คือ SHA-256 hex แบบเดียวกับ /auth/login
+// ส่วน endpoint ของ admin ใช้ API key ผ่าน "Authorization: Bearer <key>"
+// ทุกคำขอของ admin (รวมถึง key ที่ไม่ถูกต้อง) ถูกบันทึกลง audit log
+type Authenticator struct {
+	service  *auth.Service
+	adminKey string
+	audit    audit.Recorder
+}
+
+// NewAuthenticator คืน authenticator ที่ใช้ auth service ตรวจรหัสผ่าน
+// ถ้า adminKey ว่าง endpoint ของ admin ทั้งหมดจะถูกปิด
+func NewAuthenticator(service *auth.Service, adminKey string, recorder audit.Recorder) *Authenticator {
+	return &Authenticator{service: service, adminKey: adminKey, audit: recorder}
+}
+
+// RequireAdmin ห่อ handler ให้เรียกได้เฉพาะผู้ที่ถือ admin API key
+func (a *Authenticator) RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.adminKey == "" {
+			writeProblem(w, r, http.StatusForbidden, codeAdminDisabled, i18n.MsgAdminDisabled)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.adminKey)) != 1 {
+			a.recordAdmin(r, audit.AdminAuthFailed, http.StatusUnauthorized)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ingoapi-admin"`)
+			writeProblem(w, r, http.StatusUnauthorized, codeAdminInvalidKey, i18n.MsgAdminInvalidKey)
+			return
+		}
+
+		r = r.WithContext(audit.WithActor(r.Context(), audit.Actor{Type: audit.ActorAdmin}))
+		sw := &statusWriter{ResponseWriter: w}
+		next(sw, r)
+		a.recordAdmin(r, audit.AdminRequest, sw.status)
+	}
+}
+
+// recordAdmin บันทึกคำขอของ admin หลังตอบไปแล้ว ถ้าบันทึกไม่สำเร็จทำได้แค่ log ไว้
+func (a *Authenticator) recordAdmin(r *http.Request, action audit.Action, status int) {
+	details := map[string]any{"method": r.Method, "path": r.URL.Path, "status": status}
+	if r.URL.RawQuery != "" {
+		details["query"] = r.URL.RawQuery
+	}
+	// ใช้ context ที่ไม่ถูกยกเลิกตาม client เพื่อให้บันทึกได้แม้ client ตัดการเชื่อมต่อไปแล้ว
+	ctx := context.WithoutCancel(r.Context())
+	if err := a.audit.Record(ctx, audit.Entry{Action: action, Details: details}); err != nil {
+		log.Printf("audit %s %s: %v", r.Method, r.URL.Path, err)
+	}
+}
+
+// RequireUser ห่อ handler ให้เรียกได้เฉพาะผู้ใช้ที่ยืนยันตัวตนสำเร็จ
+func (a *Authenticator) RequireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		email, rawPassword, ok := r.BasicAuth()
+		if !ok {
+			unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+			return
+		}
+		passwordHex, ok := normalizeSHA256Hex(rawPassword)
+		if !ok {
+			unauthorized(w, r, codeUnauthenticated, i18n.MsgFieldSHA256Hex, "password")
+			return
+		}
+
+		u, err := a.service.Authenticate(r.Context(), email, passwordHex)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				w.Header().Set("WWW-Authenticate", basicChallenge)
+			}
+			writeError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), currentUserKey, u)
+		ctx = audit.WithActor(ctx, audit.Actor{Type: audit.ActorUser, ID: u.ID})
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// currentUser คืนผู้ใช้ที่ RequireUser แนบไว้ใน context
+func currentUser(ctx context.Context) (user.User, bool) {
+	u, ok := ctx.Value(currentUserKey).(user.User)
+	return u, ok
+}
+
+// basicChallenge บอก client ว่าเส้นทางของผู้ใช้ต้องยืนยันตัวตนด้วย HTTP Basic auth
+const basicChallenge = `Basic realm="ingoapi", charset="UTF-8"`
+
+func unauthorized(w http.ResponseWriter, r *http.Request, code string, msg i18n.Message, args ...any) {
+	w.Header().Set("WWW-Authenticate", basicChallenge)
+	writeProblem(w, r, http.StatusUnauthorized, code, msg, args...)
+}
@@ -0,0 +1,142 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"fristGoproject/internal/httpapi/dto"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/org"
+)
+
+// OrgHandler รวม endpoint ที่เกี่ยวกับองค์กร สมาชิก และคำเชิญ
+type OrgHandler struct {
+	service *org.Service
+}
+
+// NewOrgHandler คืน handler ที่เชื่อมกับ org service เรียบร้อยแล้ว
+func NewOrgHandler(service *org.Service) *OrgHandler {
+	return &OrgHandler{service: service}
+}
+
+// Organizations คืนองค์กรที่ผู้ใช้เป็นสมาชิก
+func (h *OrgHandler) Organizations(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+
+	orgs, err := h.service.ListForUser(r.Context(), u.ID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, orgs)
+}
+
+// CreateOrganization สร้างองค์กรใหม่ ผู้สร้างเป็น owner
+func (h *OrgHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+
+	var body dto.CreateOrganizationRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	created, err := h.service.Create(r.Context(), u.ID, body.Name, body.Slug)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// Members คืนสมาชิกของ tenant ปัจจุบัน
+func (h *OrgHandler) Members(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		writeError(w, r, org.ErrTenantRequired)
+		return
+	}
+
+	members, err := h.service.Members(r.Context(), tenant.OrgID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, members)
+}
+
+// ChangeRole เปลี่ยน role ของสมาชิกใน tenant ปัจจุบัน (owner/admin เท่านั้น)
+func (h *OrgHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		writeError(w, r, org.ErrTenantRequired)
+		return
+	}
+
+	var body dto.ChangeRoleRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	if err := h.service.ChangeRole(r.Context(), tenant, body.UserID, org.Role(body.Role)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": i18n.T(i18n.FromContext(r.Context()), i18n.MsgRoleChanged),
+	})
+}
+
+// Invite ส่งคำเชิญเข้าร่วม tenant ปัจจุบันทางอีเมล (owner/admin เท่านั้น)
+func (h *OrgHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		writeError(w, r, org.ErrTenantRequired)
+		return
+	}
+
+	var body dto.InviteMemberRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	inv, err := h.service.Invite(r.Context(), tenant, body.Email, org.Role(body.Role))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+// AcceptInvitation ใช้ token จากอีเมลเข้าร่วมองค์กร ผู้ใช้ต้องล็อกอินด้วยอีเมลที่ถูกเชิญ
+// รับทั้ง GET (เปิดลิงก์ในอีเมลตรง ๆ) และ POST
+func (h *OrgHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+
+	// token มาได้ทั้งจาก ?token= (ลิงก์ในอีเมล) หรือ body ถ้ามีใน query แล้วจะไม่อ่าน body
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		var body dto.AcceptInvitationRequest
+		if !decodeJSON(w, r, &body) {
+			return
+		}
+		token = body.Token
+	}
+
+	inv, err := h.service.Accept(r.Context(), u, token)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inv)
+}
@@ -0,0 +1,202 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"fristGoproject/internal/audit"
+	"fristGoproject/internal/auth"
+	"fristGoproject/internal/avatar"
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/httpapi/dto"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/org"
+	"fristGoproject/internal/user"
+	"fristGoproject/internal/webhook"
+	"fristGoproject/pkg/jsonschema"
+	"fristGoproject/pkg/password"
+)
+
+// problemContentType คือ media type ของ error response ตาม RFC 9457
+const problemContentType = "application/problem+json"
+
+// code ของ error ที่ไม่ได้มาจาก domain ใด domain หนึ่ง
+// code เป็นสัญญากับ client แล้ว ห้ามเปลี่ยนชื่อ เพิ่มใหม่ได้อย่างเดียว
+const (
+	codeInvalidJSON      = "request.invalid_json"
+	codeValidationFailed = "request.validation_failed"
+	codeInvalidParameter = "request.invalid_parameter"
+	codeTooLarge         = "request.too_large"
+	codeUnsupportedMedia = "request.unsupported_media_type"
+	codeUnauthenticated  = "auth.unauthenticated"
+	codeAdminDisabled    = "admin.disabled"
+	codeAdminInvalidKey  = "admin.invalid_key"
+	codeRouteNotFound    = "route.not_found"
+	codeMethodNotAllowed = "route.method_not_allowed"
+	codeInternal         = "server.internal"
+	codeServerBusy       = "server.busy"
+	codeConflict         = "request.conflict"
+)
+
+// domainErrors แม็ป error ของ service เป็น HTTP status และ code ที่ client ใช้ตัดสินใจได้
+// ตรวจตามลำดับด้วย errors.Is error ที่ไม่อยู่ในตารางถือเป็น 500 และไม่ส่งข้อความภายในออกไป
+var domainErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{auth.ErrInvalidCredentials, http.StatusUnauthorized, "auth.invalid_credentials"},
+	{auth.ErrEmailInUse, http.StatusConflict, "auth.email_in_use"},
+	{auth.ErrInvalidInput, http.StatusBadRequest, "auth.invalid_input"},
+	{user.ErrNotFound, http.StatusNotFound, "user.not_found"},
+	{user.ErrInvalidProfile, http.StatusUnprocessableEntity, "user.invalid_profile"},
+	{org.ErrInvalidInput, http.StatusBadRequest, "org.invalid_input"},
+	{org.ErrTenantRequired, http.StatusBadRequest, "org.tenant_required"},
+	{org.ErrNotMember, http.StatusForbidden, "org.not_member"},
+	{org.ErrForbidden, http.StatusForbidden, "org.forbidden"},
+	{org.ErrSlugInUse, http.StatusConflict, "org.slug_in_use"},
+	{org.ErrInvalidInvitation, http.StatusGone, "org.invalid_invitation"},
+	{webhook.ErrInvalidEndpoint, http.StatusBadRequest, "webhook.invalid_request"},
+	{webhook.ErrEndpointNotFound, http.StatusNotFound, "webhook.endpoint_not_found"},
+	{webhook.ErrDeliveryNotFound, http.StatusNotFound, "webhook.delivery_not_found"},
+	{avatar.ErrUnsupportedType, http.StatusUnsupportedMediaType, "avatar.unsupported_type"},
+	{avatar.ErrInvalidImage, http.StatusUnprocessableEntity, "avatar.invalid_image"},
+	{db.ErrSerialization, http.StatusServiceUnavailable, codeServerBusy},
+	{password.ErrBusy, http.StatusServiceUnavailable, codeServerBusy},
+	{db.ErrForeignKeyViolation, http.StatusConflict, codeConflict},
+}
+
+// writeError ตอบ error จาก service เป็น problem+json ตามตาราง domainErrors ในภาษาของคำขอ
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	lang := i18n.FromContext(r.Context())
+	for _, m := range domainErrors {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+		p := dto.Problem{Status: m.status, Code: m.code, Detail: localize(err, m.err, lang)}
+		var details jsonschema.ValidationErrors
+		if errors.As(err, &details) {
+			for _, d := range details {
+				p.Errors = append(p.Errors, dto.FieldError{Field: "attributes" + d.Path, Code: "schema", Message: d.Message})
+			}
+		}
+		if m.status == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", "1")
+			p.Detail = i18n.T(lang, i18n.MsgServerBusy)
+		}
+		if m.err == db.ErrForeignKeyViolation {
+			// ข้อความของ driver มีชื่อตารางและ constraint จึงตอบเป็นข้อความกลางแทน
+			p.Detail = i18n.T(lang, i18n.MsgConflict)
+		}
+		sendProblem(w, r, p)
+		return
+	}
+
+	log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	writeProblem(w, r, http.StatusInternalServerError, codeInternal, i18n.MsgInternal)
+}
+
+// localize แปล err ที่ตรงกับ sentinel เป็นภาษา lang
+// service ห่อรายละเอียดแบบ fmt.Errorf("%w: %w", sentinel, i18n.NewError(...)) จึงแปลได้ทั้งสองส่วน
+// chain แบบอื่น (เช่น "insert user: %w") ใช้เฉพาะข้อความของ sentinel เพื่อไม่ส่งข้อความภายในออกไป
+func localize(err, sentinel error, lang i18n.Lang) string {
+	msg := sentinel.Error()
+	var le *i18n.Error
+	if errors.As(sentinel, &le) {
+		msg = le.Localize(lang)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, part := range joined.Unwrap() {
+			if part != sentinel && errors.As(part, &le) {
+				return msg + ": " + le.Localize(lang)
+			}
+		}
+	}
+	return msg
+}
+
+// writeProblem ตอบ problem+json ที่ไม่มีรายละเอียดราย field โดยแปล msg เป็นภาษาของคำขอ
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code string, msg i18n.Message, args ...any) {
+	detail := i18n.T(i18n.FromContext(r.Context()), msg, args...)
+	sendProblem(w, r, dto.Problem{Status: status, Code: code, Detail: detail})
+}
+
+// writeInvalid ตอบพร้อม field ที่ไม่ผ่านการตรวจ
+// เนื้อหาของ body ผิด (codeValidationFailed) ได้ 422 ส่วน query/header/path ผิดได้ 400
+func writeInvalid(w http.ResponseWriter, r *http.Request, code string, fields ...dto.FieldError) {
+	detail := i18n.T(i18n.FromContext(r.Context()), i18n.MsgInvalidInput)
+	if len(fields) == 1 {
+		detail = fields[0].Message
+	}
+	status := http.StatusBadRequest
+	if code == codeValidationFailed {
+		status = http.StatusUnprocessableEntity
+	}
+	sendProblem(w, r, dto.Problem{Status: status, Code: code, Detail: detail, Errors: fields})
+}
+
+// fieldError สร้าง field error จากข้อความกลุ่ม i18n.MsgField* (อาร์กิวเมนต์แรกของข้อความคือชื่อ field)
+// code ของ field คือ ID ของข้อความที่ตัด "field." ออก เช่น required, sha256_hex
+func fieldError(r *http.Request, field string, msg i18n.Message, args ...any) dto.FieldError {
+	return dto.FieldError{
+		Field:   field,
+		Code:    strings.TrimPrefix(string(msg), "field."),
+		Message: i18n.T(i18n.FromContext(r.Context()), msg, append([]any{field}, args...)...),
+	}
+}
+
+// sendProblem เติม field มาตรฐานที่เหลือแล้วเขียน response
+// ใช้ type "about:blank" ตาม RFC 9457 ความหมายของ error อยู่ใน code
+func sendProblem(w http.ResponseWriter, r *http.Request, p dto.Problem) {
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	p.Instance = r.URL.Path
+	if info, ok := audit.RequestFromContext(r.Context()); ok {
+		p.RequestID = info.ID
+	}
+
+	h := w.Header()
+	h.Del("Content-Disposition")
+	h.Set("Content-Type", problemContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// problemFallback แปลง 404/405 ที่ ServeMux ตอบเป็น text ให้เป็น problem+json
+// ServeMux ไม่มี hook ให้แก้ response ของตัวเอง จึงถาม pattern ก่อน ถ้าไม่มี pattern ที่ตรงแปลว่าจะได้ 404 หรือ 405
+func problemFallback(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &headerRecorder{header: http.Header{}}
+		h.ServeHTTP(rec, r)
+		switch rec.status {
+		case http.StatusMethodNotAllowed:
+			w.Header().Set("Allow", rec.header.Get("Allow"))
+			writeProblem(w, r, rec.status, codeMethodNotAllowed, i18n.MsgMethodNotAllowed, r.Method)
+		case http.StatusNotFound:
+			writeProblem(w, r, rec.status, codeRouteNotFound, i18n.MsgRouteNotFound)
+		default:
+			// redirect หรือกรณีอื่นที่ ServeMux จัดการเอง
+			mux.ServeHTTP(w, r)
+		}
+	})
+}
+
+// headerRecorder เก็บเฉพาะ header และ status ที่ handler ของ ServeMux ตอบ ตัว body ทิ้งไป
+type headerRecorder struct {
+	header http.Header
+	status int
+}
+
+func (h *headerRecorder) Header() http.Header         { return h.header }
+func (h *headerRecorder) Write(b []byte) (int, error) { return len(b), nil }
+func (h *headerRecorder) WriteHeader(status int)      { h.status = status }
@@ -1,8 +1,14 @@
 package httpapi
 
-import "net/http"
+import (
+	"expvar"
+	"net/http"
+	"strconv"
+)
 
 // Router ช่วยรวบรวม route ต่าง ๆ ไว้ที่เดียว
+// ทุกเส้นทางลงทะเบียนเป็น pattern แบบ "METHOD /path" ของ http.ServeMux
+// เรียกผิดเมธอดจึงได้ 405 พร้อม header Allow จาก ServeMux เอง handler ไม่ต้องตรวจเมธอดซ้ำ
 type Router struct {
 	mux *http.ServeMux
 }
@@ -12,30 +18,116 @@ func NewRouter() *Router {
 	return &Router{mux: http.NewServeMux()}
 }
 
+// handle ลงทะเบียน method+path ใต้ APIPrefix และ path เดิมที่ไม่มีเวอร์ชันเป็น alias ที่เลิกใช้แล้ว
+func (r *Router) handle(method, path string, h http.HandlerFunc) {
+	r.route(method, path, h)
+	r.legacy(method, path, h)
+}
+
+// route ลงทะเบียนเฉพาะใต้ APIPrefix ใช้กับเส้นทางที่เพิ่มหลังมีเวอร์ชัน
+func (r *Router) route(method, path string, h http.HandlerFunc) {
+	r.mux.HandleFunc(method+" "+APIPrefix+path, h)
+}
+
+// legacy ลงทะเบียน path ที่ไม่มีเวอร์ชัน โดยตอบ header บอกว่าเลิกใช้แล้วและเส้นทางใหม่อยู่ที่ไหน
+func (r *Router) legacy(method, path string, h http.HandlerFunc) {
+	r.mux.HandleFunc(method+" "+path, deprecated(APIPrefix+path, h))
+}
+
+// deprecated เติม Deprecation (RFC 9745), Sunset (RFC 8594) และ Link ไปยัง successor ให้ทุก response
+func deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(legacyDeprecatedAt.Unix(), 10)
+	sunset := legacySunset.Format(http.TimeFormat)
+	link := "<" + successor + `>; rel="successor-version"`
+	return func(w http.ResponseWriter, req *http.Request) {
+		h := w.Header()
+		h.Set("Deprecation", deprecation)
+		h.Set("Sunset", sunset)
+		h.Add("Link", link)
+		next(w, req)
+	}
+}
+
 // RegisterAuthRoutes แม็ปเส้นทางที่เกี่ยวข้องกับ auth
+// เส้นทางที่รับ JSON ทุกเส้นห่อด้วย limitJSON ไว้นอกสุด คำขอที่ผิดชนิดหรือใหญ่เกินจึงถูกปัดก่อนตรวจรหัสผ่าน
 func (r *Router) RegisterAuthRoutes(handler *AuthHandler) {
-	r.mux.HandleFunc(AuthRegisterPath, handler.Register)
-	r.mux.HandleFunc(AuthLoginPath, handler.Login)
-	r.mux.HandleFunc(AuthChangePasswordPath, handler.ChangePassword)
+	r.handle(http.MethodPost, AuthRegisterPath, limitJSON(maxSmallBody, handler.Register))
+	r.handle(http.MethodPost, AuthLoginPath, limitJSON(maxLoginBody, handler.Login))
+	r.handle(http.MethodPost, AuthChangePasswordPath, limitJSON(maxSmallBody, handler.ChangePassword))
 }
 
 // RegisterUserRoutes แม็ปเส้นทางที่เกี่ยวข้องกับข้อมูลผู้ใช้
-func (r *Router) RegisterUserRoutes(handler *UserHandler) {
-	r.mux.HandleFunc(UserListPath, handler.List)
+// ทุกเส้นทางต้องผ่าน authn ก่อน ส่วนรายชื่อผู้ใช้ถูกจำกัดตาม tenant ของผู้เรียก
+func (r *Router) RegisterUserRoutes(handler *UserHandler, authn *Authenticator, tenancy *Tenancy) {
+	r.handle(http.MethodGet, UserListPath, authn.RequireUser(tenancy.Require(handler.List)))
+	r.route(http.MethodGet, UserPath, authn.RequireUser(tenancy.Require(handler.Get)))
+	r.handle(http.MethodGet, UserMePath, authn.RequireUser(handler.Me))
+	r.handle(http.MethodPatch, UserMePath, limitJSON(maxProfileBody, authn.RequireUser(handler.UpdateMe)))
+	r.handle(http.MethodDelete, UserMePath, authn.RequireUser(handler.DeleteMe))
+	r.handle(http.MethodGet, UserExportPath, authn.RequireUser(handler.Export))
+}
+
+// RegisterOrgRoutes แม็ปเส้นทางขององค์กร เส้นทางที่ทำงานกับ tenant ปัจจุบันต้องผ่าน tenancy
+func (r *Router) RegisterOrgRoutes(handler *OrgHandler, authn *Authenticator, tenancy *Tenancy) {
+	r.handle(http.MethodGet, OrgPath, authn.RequireUser(handler.Organizations))
+	r.handle(http.MethodPost, OrgPath, limitJSON(maxSmallBody, authn.RequireUser(handler.CreateOrganization)))
+	r.handle(http.MethodGet, OrgMembersPath, authn.RequireUser(tenancy.Require(handler.Members)))
+	r.handle(http.MethodPatch, OrgMembersPath, limitJSON(maxSmallBody, authn.RequireUser(tenancy.Require(handler.ChangeRole))))
+	r.handle(http.MethodPost, OrgInvitationsPath, limitJSON(maxSmallBody, authn.RequireUser(tenancy.Require(handler.Invite))))
+	r.handle(http.MethodPost, InvitationAcceptPath, limitJSON(maxSmallBody, authn.RequireUser(handler.AcceptInvitation)))
+	// ลิงก์ในอีเมลถูกเปิดด้วย GET: browser จะถามรหัสผ่านผ่าน Basic auth แล้วรับคำเชิญให้เลย
+	// token อยู่แค่ในอีเมลของผู้ถูกเชิญและต้องล็อกอินด้วยอีเมลนั้น คนอื่นจึงหลอกให้กดรับแทนไม่ได้
+	r.handle(http.MethodGet, InvitationAcceptPath, authn.RequireUser(handler.AcceptInvitation))
+}
+
+// RegisterAvatarRoutes แม็ปเส้นทางอัปโหลด/ลบรูปโปรไฟล์ (ต้องล็อกอิน)
+func (r *Router) RegisterAvatarRoutes(handler *AvatarHandler, authn *Authenticator) {
+	r.handle(http.MethodPut, UserAvatarPath, authn.RequireUser(handler.Upload))
+	r.handle(http.MethodDelete, UserAvatarPath, authn.RequireUser(handler.Remove))
+}
+
+// RegisterAdminRoutes แม็ปเส้นทางสำหรับผู้ดูแลระบบ (ต้องมี admin API key)
+// metrics คือค่าจาก expvar ทั้งหมด (เช่น password_hash, memstats) ไม่เปิดที่ /debug/vars เพราะต้องผ่าน admin key
+func (r *Router) RegisterAdminRoutes(handler *AdminHandler, authn *Authenticator) {
+	r.handle(http.MethodPost, AdminUserImportPath, authn.RequireAdmin(handler.ImportUsers))
+	r.handle(http.MethodGet, AdminUserExportPath, authn.RequireAdmin(handler.ExportUsers))
+	r.route(http.MethodGet, AdminMetricsPath, authn.RequireAdmin(expvar.Handler().ServeHTTP))
+}
+
+// RegisterWebhookRoutes แม็ปเส้นทางจัดการ webhook (ต้องมี admin API key)
+// การลบใน v1 ระบุ id ใน path ส่วน alias เดิมยังรับ DELETE /admin/webhooks?id=
+func (r *Router) RegisterWebhookRoutes(handler *WebhookHandler, authn *Authenticator) {
+	r.handle(http.MethodGet, AdminWebhooksPath, authn.RequireAdmin(handler.ListEndpoints))
+	r.handle(http.MethodPost, AdminWebhooksPath, limitJSON(maxSmallBody, authn.RequireAdmin(handler.CreateEndpoint)))
+	r.route(http.MethodDelete, AdminWebhookPath, authn.RequireAdmin(handler.DeleteEndpoint))
+	r.legacy(http.MethodDelete, AdminWebhooksPath, authn.RequireAdmin(handler.DeleteEndpoint))
+	r.handle(http.MethodGet, AdminWebhookDeliveriesPath, authn.RequireAdmin(handler.Deliveries))
+	r.handle(http.MethodPost, AdminWebhookReplayPath, limitJSON(maxSmallBody, authn.RequireAdmin(handler.Replay)))
+}
+
+// RegisterAuditRoutes แม็ปเส้นทางค้นหา audit log (ต้องมี admin API key)
+func (r *Router) RegisterAuditRoutes(handler *AuditHandler, authn *Authenticator) {
+	r.handle(http.MethodGet, AdminAuditPath, authn.RequireAdmin(handler.List))
+}
+
+// ServeMedia เสิร์ฟไฟล์ที่ LocalStore เก็บไว้ (ไม่ต้องเรียกถ้าใช้ S3)
+func (r *Router) ServeMedia(dir string) {
+	fs := http.FileServer(http.Dir(dir))
+	r.mux.Handle(http.MethodGet+" "+MediaPathPrefix, http.StripPrefix(MediaPathPrefix, fs))
 }
 
 // ServeDocs เปิดให้เข้าถึงไฟล์เอกสาร OpenAPI และหน้า Swagger UI
 func (r *Router) ServeDocs(dir string) {
 	fs := http.FileServer(http.Dir(dir))
-	r.mux.Handle(DocsPathPrefix, http.StripPrefix(DocsPathPrefix, fs))
+	r.mux.Handle(http.MethodGet+" "+DocsPathPrefix, http.StripPrefix(DocsPathPrefix, fs))
 
 	// handle /docs (ไม่มีเครื่องหมาย / ปิดท้าย) ให้ redirect ไปยัง prefix ที่ถูกต้อง
-	r.mux.HandleFunc("/docs", func(w http.ResponseWriter, req *http.Request) {
+	r.mux.HandleFunc(http.MethodGet+" /docs", func(w http.ResponseWriter, req *http.Request) {
 		http.Redirect(w, req, DocsPathPrefix, http.StatusPermanentRedirect)
 	})
 }
 
 // Mux คืนค่า http.Handler เพื่อใช้กับ http.Server
 func (r *Router) Mux() http.Handler {
-	return corsMiddleware(r.mux)
+	return corsMiddleware(requestContext(negotiateLanguage(primaryForWrites(problemFallback(r.mux)))))
 }
@@ -1,11 +1,40 @@
 package httpapi
 
+import "time"
+
 // ประกาศเส้นทางทั้งหมดไว้ที่ไฟล์เดียว
 // เวลาเปลี่ยน path จะได้แก้เฉพาะตรงนี้แล้วไฟล์อื่นจะตามเอง
+// path ของ API เขียนแบบไม่มีเวอร์ชัน Router จะเติม APIPrefix ให้ตอนลงทะเบียน
 const (
-	AuthRegisterPath       = "/auth/register"
-	AuthLoginPath          = "/auth/login"
-	AuthChangePasswordPath = "/auth/change-password"
-	UserListPath           = "/users"
-	DocsPathPrefix         = "/docs/"
+	AuthRegisterPath           = "/auth/register"
+	AuthLoginPath              = "/auth/login"
+	AuthChangePasswordPath     = "/auth/change-password"
+	UserListPath               = "/users"
+	UserPath                   = "/users/{id}"
+	UserMePath                 = "/users/me"
+	UserExportPath             = "/users/me/export"
+	UserAvatarPath             = "/users/me/avatar"
+	OrgPath                    = "/orgs"
+	OrgMembersPath             = "/orgs/members"
+	OrgInvitationsPath         = "/orgs/invitations"
+	InvitationAcceptPath       = "/invitations/accept"
+	AdminUserImportPath        = "/admin/users/import"
+	AdminUserExportPath        = "/admin/users/export"
+	AdminWebhooksPath          = "/admin/webhooks"
+	AdminWebhookPath           = "/admin/webhooks/{id}"
+	AdminWebhookDeliveriesPath = "/admin/webhooks/deliveries"
+	AdminWebhookReplayPath     = "/admin/webhooks/deliveries/replay"
+	AdminAuditPath             = "/admin/audit"
+	AdminMetricsPath           = "/admin/metrics"
+	DocsPathPrefix             = "/docs/"
+	MediaPathPrefix            = "/media/"
+)
+
+// APIPrefix คือเวอร์ชันปัจจุบันของ API ทุกเส้นทางของ API อยู่ใต้ prefix นี้
+const APIPrefix = "/v1"
+
+// path เดิมที่ไม่มีเวอร์ชันยังใช้ได้จนถึง legacySunset โดยตอบ header Deprecation/Sunset บอก client
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunset       = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
 )
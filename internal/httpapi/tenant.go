@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/org"
+)
+
+// TenantHeader คือ header ที่ client ใช้ระบุองค์กร (tenant) ของคำขอ
+const TenantHeader = "X-Org-ID"
+
+// Tenancy หา tenant ของคำขอและตรวจว่าผู้ใช้เป็นสมาชิกจริง
+type Tenancy struct {
+	orgs *org.Service
+}
+
+// NewTenancy คืน middleware ที่ใช้ org service ตรวจสมาชิกภาพ
+func NewTenancy(orgs *org.Service) *Tenancy {
+	return &Tenancy{orgs: orgs}
+}
+
+// Require ต้องใช้ต่อจาก Authenticator.RequireUser
+// อ่าน tenant จาก X-Org-ID ถ้าไม่ส่งมาและผู้ใช้อยู่องค์กรเดียวจะใช้องค์กรนั้นให้อัตโนมัติ
+func (t *Tenancy) Require(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, ok := currentUser(r.Context())
+		if !ok {
+			unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+			return
+		}
+
+		requested := 0
+		if raw := strings.TrimSpace(r.Header.Get(TenantHeader)); raw != "" {
+			id, err := strconv.Atoi(raw)
+			if err != nil || id <= 0 {
+				writeInvalid(w, r, codeInvalidParameter, fieldError(r, TenantHeader, i18n.MsgFieldPositiveInt))
+				return
+			}
+			requested = id
+		}
+
+		membership, err := t.orgs.ResolveTenant(r.Context(), u.ID, requested)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), currentTenantKey, membership)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// currentTenant คืนสมาชิกภาพของผู้เรียกใน tenant ที่ Tenancy.Require แนบไว้
+func currentTenant(ctx context.Context) (org.Membership, bool) {
+	m, ok := ctx.Value(currentTenantKey).(org.Membership)
+	return m, ok
+}
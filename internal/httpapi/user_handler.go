@@ -1,33 +1,197 @@
 package httpapi
 
 import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"fristGoproject/internal/httpapi/dto"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/org"
 	"fristGoproject/internal/user"
 )
 
+// attrQueryPrefix คือ prefix ของ query string ที่ใช้กรองด้วย custom attributes เช่น ?attr.department=eng
+const attrQueryPrefix = "attr."
+
 // UserHandler รวม endpoint ที่เกี่ยวกับข้อมูลผู้ใช้ทั่วไป
 type UserHandler struct {
 	service *user.Service
+	orgs    *org.Service
 }
 
 // NewUserHandler คืน handler ที่เชื่อมกับ user service เรียบร้อยแล้ว
-func NewUserHandler(service *user.Service) *UserHandler {
-	return &UserHandler{service: service}
+// org service ใช้ตรวจว่าผู้ใช้ที่ขอดูอยู่ใน tenant เดียวกับผู้เรียก
+func NewUserHandler(service *user.Service, orgs *org.Service) *UserHandler {
+	return &UserHandler{service: service, orgs: orgs}
 }
 
-// List คืนรายการผู้ใช้ทั้งหมดในระบบ
+// List คืนรายการผู้ใช้ใน tenant ของผู้เรียก กรองด้วย custom attributes ผ่าน ?attr.<key>=<value> ได้
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "ไม่อนุญาตให้ใช้เมธอดนี้", http.StatusMethodNotAllowed)
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		writeError(w, r, org.ErrTenantRequired)
 		return
 	}
 
-	users, err := h.service.List(r.Context())
+	filter := user.ListFilter{OrgID: tenant.OrgID, Attributes: map[string]string{}}
+	for key, values := range r.URL.Query() {
+		name, ok := strings.CutPrefix(key, attrQueryPrefix)
+		if !ok || name == "" || len(values) == 0 {
+			continue
+		}
+		filter.Attributes[name] = values[0]
+	}
+
+	users, err := h.service.List(r.Context(), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, users)
 }
+
+// Get คืนผู้ใช้ตาม id ใน path ผู้ใช้ต้องเป็นสมาชิก tenant เดียวกับผู้เรียก ไม่เช่นนั้นตอบ 404
+func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := currentTenant(r.Context())
+	if !ok {
+		writeError(w, r, org.ErrTenantRequired)
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		writeInvalid(w, r, codeInvalidParameter, fieldError(r, "id", i18n.MsgFieldPositiveInt))
+		return
+	}
+
+	// ผู้ใช้นอก tenant ตอบ 404 เหมือนไม่มีบัญชีนั้น ไม่ให้ใช้เดาว่ามีบัญชีใดอยู่ในองค์กรอื่น
+	if _, err := h.orgs.ResolveTenant(r.Context(), id, tenant.OrgID); err != nil {
+		if errors.Is(err, org.ErrNotMember) {
+			writeError(w, r, user.ErrNotFound)
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	u, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// Me คืนโปรไฟล์ของผู้ใช้ที่ล็อกอินอยู่
+func (h *UserHandler) Me(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// UpdateMe แก้ชื่อ/attributes ของผู้ใช้ที่ล็อกอินอยู่ (JSON Merge Patch)
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+
+	var body dto.UpdateProfileRequest
+
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	updated, err := h.service.UpdateProfile(r.Context(), u.ID, user.ProfileUpdate{
+		Name:       body.Name,
+		Attributes: body.Attributes,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteMe ลบบัญชีของผู้ใช้ที่ล็อกอินอยู่และข้อมูลส่วนบุคคลทั้งหมด
+func (h *UserHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+
+	if err := h.service.Erase(r.Context(), u.ID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Export ส่งข้อมูลทั้งหมดที่ระบบเก็บเกี่ยวกับผู้ใช้ที่ล็อกอินอยู่
+// ค่า default เป็น JSON ถ้าส่ง ?format=zip จะได้ไฟล์ ZIP แยกแต่ละ section
+func (h *UserHandler) Export(w http.ResponseWriter, r *http.Request) {
+	u, ok := currentUser(r.Context())
+	if !ok {
+		unauthorized(w, r, codeUnauthenticated, i18n.MsgUnauthenticated)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "zip" {
+		writeInvalid(w, r, codeInvalidParameter, fieldError(r, "format", i18n.MsgFieldOneOf, "json, zip"))
+		return
+	}
+
+	export, err := h.service.Export(r.Context(), u.ID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if format != "zip" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%d-export.json"`, u.ID))
+		writeJSON(w, http.StatusOK, export)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%d-export.zip"`, u.ID))
+	w.WriteHeader(http.StatusOK)
+	_ = writeExportZip(w, export)
+}
+
+// writeExportZip เขียน export เป็น ZIP โดยแยก profile และแต่ละ DataSource เป็นไฟล์ JSON ของตัวเอง
+func writeExportZip(w http.ResponseWriter, export user.Export) error {
+	zw := zip.NewWriter(w)
+
+	files := map[string]any{"profile.json": export.Profile}
+	for name, data := range export.Data {
+		files[name+".json"] = data
+	}
+	files["manifest.json"] = map[string]any{"exported_at": export.ExportedAt}
+
+	for name, payload := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(payload); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
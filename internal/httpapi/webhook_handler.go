@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"fristGoproject/internal/event"
+	"fristGoproject/internal/httpapi/dto"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/internal/webhook"
+)
+
+// WebhookHandler รวม endpoint ของ admin สำหรับจัดการ webhook และดูประวัติการส่ง
+type WebhookHandler struct {
+	service *webhook.Service
+}
+
+// NewWebhookHandler คืน handler ที่เชื่อมกับ webhook service เรียบร้อยแล้ว
+func NewWebhookHandler(service *webhook.Service) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// ListEndpoints คืน endpoint ทั้งหมด
+func (h *WebhookHandler) ListEndpoints(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.service.ListEndpoints(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, endpoints)
+}
+
+// CreateEndpoint ลงทะเบียน endpoint ใหม่ secret จะแสดงใน response นี้ครั้งเดียว
+func (h *WebhookHandler) CreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	var body dto.CreateWebhookRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	types := make([]event.Type, len(body.Events))
+	for i, t := range body.Events {
+		types[i] = event.Type(t)
+	}
+
+	ep, err := h.service.CreateEndpoint(r.Context(), body.URL, types, body.Description)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, ep)
+}
+
+// DeleteEndpoint ลบ endpoint พร้อมประวัติการส่ง id มาจาก path ({id})
+// หรือ ?id= สำหรับ alias เดิมที่ไม่มีเวอร์ชัน
+func (h *WebhookHandler) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	raw := r.PathValue("id")
+	if raw == "" {
+		raw = r.URL.Query().Get("id")
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		writeInvalid(w, r, codeInvalidParameter, fieldError(r, "id", i18n.MsgFieldPositiveInt))
+		return
+	}
+	if err := h.service.DeleteEndpoint(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries คืนประวัติการส่งล่าสุดก่อน
+// query: endpoint_id, status=pending|succeeded|failed, limit (default 50 สูงสุด 200)
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := webhook.DeliveryFilter{Status: webhook.Status(q.Get("status"))}
+	if raw := q.Get("endpoint_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			writeInvalid(w, r, codeInvalidParameter, fieldError(r, "endpoint_id", i18n.MsgFieldPositiveInt))
+			return
+		}
+		filter.EndpointID = id
+	}
+	if raw := q.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			writeInvalid(w, r, codeInvalidParameter, fieldError(r, "limit", i18n.MsgFieldPositiveInt))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	deliveries, err := h.service.Deliveries(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// Replay สั่งส่งรายการเดิมอีกครั้งเป็นรายการใหม่ (ส่งใน worker รอบถัดไป)
+func (h *WebhookHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	var body dto.ReplayWebhookRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	d, err := h.service.Replay(r.Context(), body.DeliveryID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, d)
+}
@@ -0,0 +1,73 @@
+package i18n
+
+var english = map[Message]string{
+	MsgInternal:         "An internal error occurred",
+	MsgServerBusy:       "The service is busy, please try again",
+	MsgConflict:         "A referenced record no longer exists or is still in use",
+	MsgInvalidJSON:      "The request body is not valid JSON",
+	MsgInvalidInput:     "The request is invalid",
+	MsgTooLarge:         "The request body is too large",
+	MsgUnsupportedMedia: "Only Content-Type: application/json is supported",
+	MsgRouteNotFound:    "No route matches the requested path",
+	MsgMethodNotAllowed: "This path does not support the %s method",
+	MsgUnauthenticated:  "Authentication is required",
+	MsgAdminDisabled:    "The admin API is not enabled",
+	MsgAdminInvalidKey:  "Invalid admin API key",
+	MsgPasswordChanged:  "Password changed",
+	MsgRoleChanged:      "Role changed",
+
+	MsgFieldRequired:    "%s is required",
+	MsgFieldSHA256Hex:   "%s must be a 64-character SHA-256 hex string",
+	MsgFieldPositiveInt: "%s must be a positive integer",
+	MsgFieldRFC3339:     "%s must be an RFC 3339 timestamp such as 2025-01-31T00:00:00Z",
+	MsgFieldOneOf:       "%s must be one of: %s",
+	MsgFieldEmail:       "%s is not a valid email address",
+	MsgFieldURL:         "%s must be an absolute http(s) URL",
+	MsgFieldFile:        "A file must be sent in the %s field as multipart/form-data",
+	MsgFieldUnreadable:  "The file in the %s field could not be read",
+	MsgFieldMinLength:   "%s must be at least %d characters long",
+	MsgFieldMaxLength:   "%s must be at most %d characters long",
+	MsgFieldMin:         "%s must be at least %d",
+	MsgFieldMax:         "%s must be at most %d",
+	MsgFieldName:        "%s may only contain letters, spaces and ' - .",
+	MsgFieldPrintable:   "%s must not contain control characters",
+	MsgFieldUnknown:     "Unknown field %s",
+	MsgFieldType:        "%s must be of type %s",
+	MsgFieldDuplicate:   "%s appears more than once",
+
+	MsgInvalidCredentials:  "Invalid email or password",
+	MsgEmailInUse:          "This email is already registered",
+	MsgUserNotFound:        "User not found",
+	MsgInvalidProfile:      "The profile is invalid",
+	MsgTenantRequired:      "An organization must be specified",
+	MsgNotMember:           "You are not a member of this organization",
+	MsgOrgForbidden:        "Your role in this organization does not allow this action",
+	MsgSlugInUse:           "This slug is already taken",
+	MsgSlugFormat:          "slug must be 2-63 characters of a-z, 0-9 or -",
+	MsgInvalidInvitation:   "The invitation is invalid or has expired",
+	MsgWebhookInvalid:      "The webhook request is invalid",
+	MsgWebhookNotFound:     "Webhook not found",
+	MsgDeliveryNotFound:    "Webhook delivery not found",
+	MsgWebhookEvents:       `At least one event (or "*") is required`,
+	MsgWebhookUnknownEvent: "Unknown event %q",
+	MsgUnsupportedImage:    "Only JPEG, PNG, GIF or WebP files are supported",
+	MsgInvalidImage:        "The image file is invalid",
+
+	MsgImportHeaderUnreadable: "The CSV header could not be read",
+	MsgImportUnknownColumn:    "Unknown column %q",
+	MsgImportEmailColumn:      "The CSV must have an email column",
+	MsgImportCSVSyntax:        "Malformed CSV: %v",
+	MsgImportColumnCount:      "Row has %d columns but the header has %d",
+	MsgImportAttributes:       "attributes must be a JSON object",
+	MsgImportInvalidJSON:      "Line is not a valid JSON object",
+	MsgImportRequired:         "email and name are required",
+	MsgImportEmail:            "email is not a valid address",
+	MsgImportDuplicateRow:     "email duplicates row %d of the same file",
+	MsgImportSchema:           "attributes do not match the schema: %v",
+	MsgImportPasswordBoth:     "Send either password or password_hash, not both",
+	MsgImportPasswordMissing:  "password or password_hash is required",
+	MsgImportPasswordFormat:   "password must be a 64-character SHA-256 hex string",
+	MsgImportHashUnknown:      "password_hash is not in a recognized format",
+
+	MsgInvitationSubject: "Invitation to join %s",
+}
@@ -0,0 +1,128 @@
+// Package i18n เก็บข้อความที่แสดงกับผู้ใช้ (API และอีเมล) แยกตามภาษา
+// ทุกข้อความอ้างด้วย Message ID ที่คงที่ ข้อความจริงอยู่ใน catalog ของแต่ละภาษา (th.go, en.go)
+// ภาษาของแต่ละคำขอเลือกจาก header Accept-Language ด้วย Negotiate แล้วส่งต่อทาง context
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang คือรหัสภาษาแบบ BCP 47 (ใช้เฉพาะ primary subtag)
+type Lang string
+
+const (
+	Thai    Lang = "th"
+	English Lang = "en"
+)
+
+// Default คือภาษาที่ใช้เมื่อ client ไม่ระบุหรือขอภาษาที่ไม่มี และเป็นภาษาของ Error()
+const Default = Thai
+
+// Supported คือภาษาที่มี catalog ครบ เรียงตามลำดับที่เลือกเมื่อ q เท่ากัน
+var Supported = []Lang{Thai, English}
+
+// Message คือ ID ของข้อความ ID เป็นสัญญากับ catalog ห้ามเปลี่ยนชื่อ
+type Message string
+
+var catalogs = map[Lang]map[Message]string{
+	Thai:    thai,
+	English: english,
+}
+
+// T คืนข้อความ id ในภาษา lang แทนค่า args แบบ fmt.Sprintf
+// ถ้าภาษานั้นไม่มีข้อความจะใช้ Default และถ้ายังไม่มีอีกจะคืน id เอง (เห็นได้ชัดตอนทดสอบว่าลืมเพิ่ม)
+func T(lang Lang, id Message, args ...any) string {
+	format, ok := catalogs[lang][id]
+	if !ok {
+		if format, ok = catalogs[Default][id]; !ok {
+			return string(id)
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Negotiate เลือกภาษาจากค่า Accept-Language (RFC 9110) เช่น "en-US,en;q=0.9,th;q=0.5"
+// เทียบเฉพาะ primary subtag ค่า q=0 ถือว่าไม่รับ และ "*" หมายถึงภาษาใดก็ได้ (ได้ Default)
+func Negotiate(header string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+		pos  int
+	}
+	var candidates []candidate
+	for i, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		primary, _, _ := strings.Cut(tag, "-")
+		if primary == "*" {
+			candidates = append(candidates, candidate{Default, q, i})
+			continue
+		}
+		if lang := Lang(primary); catalogs[lang] != nil {
+			candidates = append(candidates, candidate{lang, q, i})
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].q > candidates[b].q })
+	return candidates[0].lang
+}
+
+type langKey struct{}
+
+// WithLang แนบภาษาของคำขอไว้ใน ctx
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// FromContext คืนภาษาที่ WithLang แนบไว้ ถ้าไม่มีคืน Default
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(langKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// Error คือ error ที่แปลได้ ใช้เป็นรายละเอียดที่ห่อต่อจาก sentinel ของ service
+// เช่น fmt.Errorf("%w: %w", ErrInvalidInput, i18n.NewError(i18n.MsgNameRequired))
+// Error() คืนข้อความภาษา Default เพื่อให้ log และ CLI อ่านได้เหมือน error ทั่วไป
+type Error struct {
+	ID   Message
+	Args []any
+}
+
+// NewError คืน Error ของข้อความ id
+func NewError(id Message, args ...any) *Error {
+	return &Error{ID: id, Args: args}
+}
+
+func (e *Error) Error() string {
+	return T(Default, e.ID, e.Args...)
+}
+
+// Localize คืนข้อความของ error ในภาษา lang
+func (e *Error) Localize(lang Lang) string {
+	return T(lang, e.ID, e.Args...)
+}
@@ -0,0 +1,86 @@
+package i18n
+
+// ข้อความทั่วไปของ API
+const (
+	MsgInternal         Message = "server.internal"
+	MsgServerBusy       Message = "server.busy"
+	MsgConflict         Message = "request.conflict"
+	MsgInvalidJSON      Message = "request.invalid_json"
+	MsgInvalidInput     Message = "request.invalid_input"
+	MsgTooLarge         Message = "request.too_large"
+	MsgUnsupportedMedia Message = "request.unsupported_media_type"
+	MsgRouteNotFound    Message = "route.not_found"
+	MsgMethodNotAllowed Message = "route.method_not_allowed"
+	MsgUnauthenticated  Message = "auth.unauthenticated"
+	MsgAdminDisabled    Message = "admin.disabled"
+	MsgAdminInvalidKey  Message = "admin.invalid_key"
+	MsgPasswordChanged  Message = "auth.password_changed"
+	MsgRoleChanged      Message = "org.role_changed"
+)
+
+// ข้อความของ field, query parameter และ header ที่ไม่ผ่านการตรวจ (อาร์กิวเมนต์แรกคือชื่อ field)
+const (
+	MsgFieldRequired    Message = "field.required"
+	MsgFieldSHA256Hex   Message = "field.sha256_hex"
+	MsgFieldPositiveInt Message = "field.positive_int"
+	MsgFieldRFC3339     Message = "field.rfc3339"
+	MsgFieldOneOf       Message = "field.one_of"
+	MsgFieldEmail       Message = "field.email"
+	MsgFieldURL         Message = "field.url"
+	MsgFieldFile        Message = "field.file"
+	MsgFieldUnreadable  Message = "field.unreadable"
+	MsgFieldMinLength   Message = "field.min_length"
+	MsgFieldMaxLength   Message = "field.max_length"
+	MsgFieldMin         Message = "field.min"
+	MsgFieldMax         Message = "field.max"
+	MsgFieldName        Message = "field.name"
+	MsgFieldPrintable   Message = "field.printable"
+	MsgFieldUnknown     Message = "field.unknown"
+	MsgFieldType        Message = "field.type"
+	MsgFieldDuplicate   Message = "field.duplicate"
+)
+
+// ข้อความของ error จาก service
+const (
+	MsgInvalidCredentials  Message = "auth.invalid_credentials"
+	MsgEmailInUse          Message = "auth.email_in_use"
+	MsgUserNotFound        Message = "user.not_found"
+	MsgInvalidProfile      Message = "user.invalid_profile"
+	MsgTenantRequired      Message = "org.tenant_required"
+	MsgNotMember           Message = "org.not_member"
+	MsgOrgForbidden        Message = "org.forbidden"
+	MsgSlugInUse           Message = "org.slug_in_use"
+	MsgSlugFormat          Message = "org.slug_format"
+	MsgInvalidInvitation   Message = "org.invalid_invitation"
+	MsgWebhookInvalid      Message = "webhook.invalid_request"
+	MsgWebhookNotFound     Message = "webhook.endpoint_not_found"
+	MsgDeliveryNotFound    Message = "webhook.delivery_not_found"
+	MsgWebhookEvents       Message = "webhook.events_required"
+	MsgWebhookUnknownEvent Message = "webhook.unknown_event"
+	MsgUnsupportedImage    Message = "avatar.unsupported_type"
+	MsgInvalidImage        Message = "avatar.invalid_image"
+)
+
+// ข้อความของการนำเข้าผู้ใช้ (header ของไฟล์และผลรายแถว)
+const (
+	MsgImportHeaderUnreadable Message = "import.header_unreadable"
+	MsgImportUnknownColumn    Message = "import.unknown_column"
+	MsgImportEmailColumn      Message = "import.email_column"
+	MsgImportCSVSyntax        Message = "import.csv_syntax"
+	MsgImportColumnCount      Message = "import.column_count"
+	MsgImportAttributes       Message = "import.attributes"
+	MsgImportInvalidJSON      Message = "import.invalid_json"
+	MsgImportRequired         Message = "import.required"
+	MsgImportEmail            Message = "import.email"
+	MsgImportDuplicateRow     Message = "import.duplicate_row"
+	MsgImportSchema           Message = "import.schema"
+	MsgImportPasswordBoth     Message = "import.password_both"
+	MsgImportPasswordMissing  Message = "import.password_missing"
+	MsgImportPasswordFormat   Message = "import.password_format"
+	MsgImportHashUnknown      Message = "import.hash_unknown"
+)
+
+// ข้อความในอีเมล
+const (
+	MsgInvitationSubject Message = "mail.invitation_subject"
+)
@@ -0,0 +1,73 @@
+package i18n
+
+var thai = map[Message]string{
+	MsgInternal:         "เกิดข้อผิดพลาดภายในระบบ",
+	MsgServerBusy:       "ระบบไม่ว่าง กรุณาลองใหม่อีกครั้ง",
+	MsgConflict:         "ข้อมูลที่อ้างถึงไม่มีอยู่แล้วหรือยังถูกใช้งานอยู่",
+	MsgInvalidJSON:      "เนื้อหาไม่ใช่ JSON ที่ถูกต้อง",
+	MsgInvalidInput:     "ข้อมูลไม่ถูกต้อง",
+	MsgTooLarge:         "ข้อมูลที่ส่งมามีขนาดใหญ่เกินกำหนด",
+	MsgUnsupportedMedia: "รองรับเฉพาะ Content-Type: application/json",
+	MsgRouteNotFound:    "ไม่พบเส้นทางที่เรียก",
+	MsgMethodNotAllowed: "path นี้ไม่รองรับเมธอด %s",
+	MsgUnauthenticated:  "ต้องยืนยันตัวตนก่อนใช้งาน",
+	MsgAdminDisabled:    "ยังไม่ได้เปิดใช้งาน admin API",
+	MsgAdminInvalidKey:  "admin API key ไม่ถูกต้อง",
+	MsgPasswordChanged:  "เปลี่ยนรหัสผ่านเรียบร้อย",
+	MsgRoleChanged:      "เปลี่ยน role เรียบร้อย",
+
+	MsgFieldRequired:    "%s ต้องไม่ว่าง",
+	MsgFieldSHA256Hex:   "%s ต้องเป็น SHA-256 hex 64 ตัวอักษร",
+	MsgFieldPositiveInt: "%s ต้องเป็นจำนวนเต็มบวก",
+	MsgFieldRFC3339:     "%s ต้องเป็นเวลาแบบ RFC 3339 เช่น 2025-01-31T00:00:00Z",
+	MsgFieldOneOf:       "%s ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: %s",
+	MsgFieldEmail:       "%s รูปแบบ email ไม่ถูกต้อง",
+	MsgFieldURL:         "%s ต้องเป็น http(s) URL แบบเต็ม",
+	MsgFieldFile:        "ต้องส่งไฟล์ใน field %s แบบ multipart/form-data",
+	MsgFieldUnreadable:  "อ่านไฟล์ใน field %s ไม่สำเร็จ",
+	MsgFieldMinLength:   "%s ต้องยาวอย่างน้อย %d ตัวอักษร",
+	MsgFieldMaxLength:   "%s ต้องยาวไม่เกิน %d ตัวอักษร",
+	MsgFieldMin:         "%s ต้องมีค่าอย่างน้อย %d",
+	MsgFieldMax:         "%s ต้องมีค่าไม่เกิน %d",
+	MsgFieldName:        "%s มีได้เฉพาะตัวอักษร ช่องว่าง และ ' - .",
+	MsgFieldPrintable:   "%s ต้องไม่มีอักขระควบคุม",
+	MsgFieldUnknown:     "ไม่รู้จัก field %s",
+	MsgFieldType:        "%s ต้องเป็นชนิด %s",
+	MsgFieldDuplicate:   "%s ถูกส่งมาซ้ำ",
+
+	MsgInvalidCredentials:  "อีเมลหรือรหัสผ่านไม่ถูกต้อง",
+	MsgEmailInUse:          "email นี้มีผู้ใช้งานแล้ว",
+	MsgUserNotFound:        "ไม่พบผู้ใช้",
+	MsgInvalidProfile:      "ข้อมูลโปรไฟล์ไม่ถูกต้อง",
+	MsgTenantRequired:      "ต้องระบุองค์กรที่ต้องการใช้งาน",
+	MsgNotMember:           "คุณไม่ได้เป็นสมาชิกขององค์กรนี้",
+	MsgOrgForbidden:        "สิทธิ์ในองค์กรไม่พอสำหรับการกระทำนี้",
+	MsgSlugInUse:           "slug นี้ถูกใช้งานแล้ว",
+	MsgSlugFormat:          "slug ต้องเป็น a-z, 0-9 หรือ - ยาว 2-63 ตัวอักษร",
+	MsgInvalidInvitation:   "คำเชิญไม่ถูกต้องหรือหมดอายุแล้ว",
+	MsgWebhookInvalid:      "ข้อมูล webhook ไม่ถูกต้อง",
+	MsgWebhookNotFound:     "ไม่พบ webhook ที่ระบุ",
+	MsgDeliveryNotFound:    "ไม่พบรายการส่ง webhook ที่ระบุ",
+	MsgWebhookEvents:       `ต้องระบุ events อย่างน้อยหนึ่งรายการ (หรือ "*")`,
+	MsgWebhookUnknownEvent: "ไม่รู้จัก event %q",
+	MsgUnsupportedImage:    "รองรับเฉพาะไฟล์ JPEG, PNG, GIF หรือ WebP",
+	MsgInvalidImage:        "ไฟล์รูปไม่ถูกต้อง",
+
+	MsgImportHeaderUnreadable: "อ่าน header CSV ไม่ได้",
+	MsgImportUnknownColumn:    "ไม่รู้จักคอลัมน์ %q",
+	MsgImportEmailColumn:      "CSV ต้องมีคอลัมน์ email",
+	MsgImportCSVSyntax:        "CSV ผิดรูปแบบ: %v",
+	MsgImportColumnCount:      "มี %d คอลัมน์ แต่ header มี %d",
+	MsgImportAttributes:       "attributes ต้องเป็น JSON object",
+	MsgImportInvalidJSON:      "ไม่ใช่ JSON object ที่ถูกต้อง",
+	MsgImportRequired:         "email และ name ต้องไม่ว่าง",
+	MsgImportEmail:            "รูปแบบ email ไม่ถูกต้อง",
+	MsgImportDuplicateRow:     "email ซ้ำกับแถว %d ในไฟล์เดียวกัน",
+	MsgImportSchema:           "attributes ไม่ผ่าน schema: %v",
+	MsgImportPasswordBoth:     "ส่งได้เพียง password หรือ password_hash อย่างใดอย่างหนึ่ง",
+	MsgImportPasswordMissing:  "ต้องมี password หรือ password_hash",
+	MsgImportPasswordFormat:   "password ต้องเป็น SHA-256 hex 64 ตัวอักษร",
+	MsgImportHashUnknown:      "password_hash ไม่ใช่รูปแบบที่รู้จัก",
+
+	MsgInvitationSubject: "คำเชิญเข้าร่วม %s",
+}
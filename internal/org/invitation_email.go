@@ -0,0 +1,53 @@
+package org
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"text/template"
+	"time"
+
+	"fristGoproject/internal/i18n"
+	mailer "fristGoproject/internal/mail"
+)
+
+type invitationData struct {
+	OrgName     string
+	InviterName string
+	Role        Role
+	AcceptURL   string
+	ExpiresAt   time.Time
+}
+
+// เนื้ออีเมลแยกไฟล์ตามภาษา ชื่อไฟล์เป็น <ชื่อ>.<lang>.tmpl
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var invitationTemplates = parseLocalized("invitation")
+
+// parseLocalized อ่าน template ของทุกภาษาใน i18n.Supported ตอนเริ่มโปรแกรม ภาษาไหนขาดไฟล์จะ panic ทันที
+func parseLocalized(name string) map[i18n.Lang]*template.Template {
+	templates := make(map[i18n.Lang]*template.Template, len(i18n.Supported))
+	for _, lang := range i18n.Supported {
+		file := fmt.Sprintf("templates/%s.%s.tmpl", name, lang)
+		templates[lang] = template.Must(template.ParseFS(templateFS, file))
+	}
+	return templates
+}
+
+// renderInvitation สร้างอีเมลคำเชิญในภาษา lang (ภาษาของผู้เชิญตอนส่งคำขอ เพราะยังไม่รู้ภาษาของผู้รับ)
+func renderInvitation(lang i18n.Lang, data invitationData) (mailer.Message, error) {
+	tmpl, ok := invitationTemplates[lang]
+	if !ok {
+		tmpl = invitationTemplates[i18n.Default]
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return mailer.Message{}, fmt.Errorf("render invitation email: %w", err)
+	}
+	return mailer.Message{
+		Subject: i18n.T(lang, i18n.MsgInvitationSubject, data.OrgName),
+		Body:    body.String(),
+	}, nil
+}
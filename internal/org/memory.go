@@ -0,0 +1,241 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/user"
+)
+
+// MemoryRepository เก็บองค์กร สมาชิก และคำเชิญไว้ในหน่วยความจำ ใช้คู่กับ user.MemoryRepository
+// ตอนรันเซิร์ฟเวอร์โดยไม่มีฐานข้อมูล พฤติกรรม (ลำดับผลลัพธ์ และ error ที่ห่อ pgx.ErrNoRows) ตรงกับฝั่ง Postgres
+type MemoryRepository struct {
+	mu          sync.RWMutex
+	users       user.Repository
+	orgs        map[int]Organization
+	memberships map[[2]int]Membership
+	invitations map[int]Invitation
+	nextOrgID   int
+	nextInvID   int
+	now         func() time.Time
+}
+
+// NewMemoryRepository คืน repository ว่าง users ใช้ดึงอีเมลและชื่อตอนแสดงรายชื่อสมาชิก
+func NewMemoryRepository(users user.Repository) *MemoryRepository {
+	return &MemoryRepository{
+		users:       users,
+		orgs:        map[int]Organization{},
+		memberships: map[[2]int]Membership{},
+		invitations: map[int]Invitation{},
+		nextOrgID:   1,
+		nextInvID:   1,
+		// Postgres เก็บเวลาละเอียดระดับไมโครวินาที
+		now: func() time.Time { return time.Now().Truncate(time.Microsecond) },
+	}
+}
+
+// MemberIDs คืน id ของสมาชิกทุกคนในองค์กร ใช้กับ user.MemoryRepository.SetMemberLookup
+func (m *MemoryRepository) MemberIDs(ctx context.Context, orgID int) ([]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var ids []int
+	for key := range m.memberships {
+		if key[0] == orgID {
+			ids = append(ids, key[1])
+		}
+	}
+	return ids, nil
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, o Organization, ownerID int) (Organization, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.orgs {
+		if existing.Slug == o.Slug {
+			return Organization{}, fmt.Errorf("insert organization: %w", ErrSlugInUse)
+		}
+	}
+
+	now := m.now()
+	o.ID = m.nextOrgID
+	m.nextOrgID++
+	o.CreatedAt = now
+	m.orgs[o.ID] = o
+	m.memberships[[2]int{o.ID, ownerID}] = Membership{OrgID: o.ID, UserID: ownerID, Role: RoleOwner, CreatedAt: now}
+	return o, nil
+}
+
+func (m *MemoryRepository) FindByID(ctx context.Context, id int) (Organization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	o, ok := m.orgs[id]
+	if !ok {
+		return Organization{}, fmt.Errorf("organization not found: %w", pgx.ErrNoRows)
+	}
+	return o, nil
+}
+
+func (m *MemoryRepository) FindBySlug(ctx context.Context, slug string) (Organization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, o := range m.orgs {
+		if o.Slug == slug {
+			return o, nil
+		}
+	}
+	return Organization{}, fmt.Errorf("organization not found: %w", pgx.ErrNoRows)
+}
+
+func (m *MemoryRepository) ListForUser(ctx context.Context, userID int) ([]UserOrganization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	orgs := []UserOrganization{}
+	for key, ms := range m.memberships {
+		if key[1] == userID {
+			orgs = append(orgs, UserOrganization{Organization: m.orgs[key[0]], Role: ms.Role})
+		}
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+	return orgs, nil
+}
+
+func (m *MemoryRepository) FindMembership(ctx context.Context, orgID, userID int) (Membership, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ms, ok := m.memberships[[2]int{orgID, userID}]
+	if !ok {
+		return Membership{}, fmt.Errorf("membership not found: %w", pgx.ErrNoRows)
+	}
+	return ms, nil
+}
+
+func (m *MemoryRepository) ListMembers(ctx context.Context, orgID int) ([]Member, error) {
+	m.mu.RLock()
+	var memberships []Membership
+	for key, ms := range m.memberships {
+		if key[0] == orgID {
+			memberships = append(memberships, ms)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(memberships, func(i, j int) bool { return memberships[i].CreatedAt.Before(memberships[j].CreatedAt) })
+	members := []Member{}
+	for _, ms := range memberships {
+		u, err := m.users.FindByID(ctx, ms.UserID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// ฝั่ง Postgres ลบสมาชิกภาพตามผู้ใช้ (ON DELETE CASCADE)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("query members: %w", err)
+		}
+		members = append(members, Member{UserID: u.ID, Email: u.Email, Name: u.Name, Role: ms.Role, JoinedAt: ms.CreatedAt})
+	}
+	return members, nil
+}
+
+func (m *MemoryRepository) UpdateRole(ctx context.Context, orgID, userID int, role Role) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := [2]int{orgID, userID}
+	ms, ok := m.memberships[key]
+	if !ok {
+		return fmt.Errorf("membership not found: %w", pgx.ErrNoRows)
+	}
+	ms.Role = role
+	m.memberships[key] = ms
+	return nil
+}
+
+func (m *MemoryRepository) CreateInvitation(ctx context.Context, inv Invitation) (Invitation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inv.ID = m.nextInvID
+	m.nextInvID++
+	inv.CreatedAt = m.now()
+	m.invitations[inv.ID] = inv
+	return inv, nil
+}
+
+func (m *MemoryRepository) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (Invitation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, inv := range m.invitations {
+		if inv.TokenHash == tokenHash {
+			return inv, nil
+		}
+	}
+	return Invitation{}, fmt.Errorf("invitation not found: %w", pgx.ErrNoRows)
+}
+
+func (m *MemoryRepository) AcceptInvitation(ctx context.Context, invitationID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inv, ok := m.invitations[invitationID]
+	if !ok || inv.AcceptedAt != nil {
+		return fmt.Errorf("invitation already accepted: %w", pgx.ErrNoRows)
+	}
+	now := m.now()
+	inv.AcceptedAt = &now
+	m.invitations[invitationID] = inv
+
+	key := [2]int{inv.OrgID, userID}
+	if _, exists := m.memberships[key]; !exists {
+		m.memberships[key] = Membership{OrgID: inv.OrgID, UserID: userID, Role: inv.Role, CreatedAt: now}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) ListInvitationsByEmail(ctx context.Context, email string) ([]Invitation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	invitations := []Invitation{}
+	for _, inv := range m.invitations {
+		if inv.Email == email {
+			invitations = append(invitations, inv)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].CreatedAt.Before(invitations[j].CreatedAt) })
+	return invitations, nil
+}
+
+func (m *MemoryRepository) DeleteMemberships(ctx context.Context, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.memberships {
+		if key[1] == userID {
+			delete(m.memberships, key)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) DeleteInvitationsByEmail(ctx context.Context, email string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, inv := range m.invitations {
+		if inv.Email == email {
+			delete(m.invitations, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) ClearInviter(ctx context.Context, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, inv := range m.invitations {
+		if inv.InvitedBy != nil && *inv.InvitedBy == userID {
+			inv.InvitedBy = nil
+			m.invitations[id] = inv
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package org
+
+import "time"
+
+// Role คือสิทธิ์ของสมาชิกภายในองค์กรหนึ่ง (สิทธิ์แยกตาม tenant)
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+)
+
+// Valid บอกว่าเป็น role ที่ระบบรู้จักหรือไม่
+func (r Role) Valid() bool {
+	return r == RoleOwner || r == RoleAdmin || r == RoleMember
+}
+
+// CanManage บอกว่า role นี้เชิญสมาชิก/เปลี่ยน role คนอื่นได้หรือไม่
+func (r Role) CanManage() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
+
+// Organization แทนแถวเดียวในตาราง organizations (หนึ่ง tenant)
+type Organization struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Membership แทนแถวเดียวในตาราง memberships
+type Membership struct {
+	OrgID     int       `json:"org_id"`
+	UserID    int       `json:"user_id"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserOrganization คือองค์กรที่ผู้ใช้เป็นสมาชิกพร้อม role ของผู้ใช้ในองค์กรนั้น
+type UserOrganization struct {
+	Organization
+	Role Role `json:"role"`
+}
+
+// Member คือสมาชิกในองค์กรพร้อมข้อมูลพื้นฐานของผู้ใช้
+type Member struct {
+	UserID   int       `json:"user_id"`
+	Email    string    `json:"email"`
+	Name     string    `json:"name"`
+	Role     Role      `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Invitation แทนแถวเดียวในตาราง invitations (เก็บเฉพาะ hash ของ token)
+type Invitation struct {
+	ID         int        `json:"id"`
+	OrgID      int        `json:"org_id"`
+	Email      string     `json:"email"`
+	Role       Role       `json:"role"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  *int       `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,342 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/db"
+)
+
+// Repository กำหนดพฤติกรรมที่ service ใช้อ่าน/เขียนข้อมูลองค์กร สมาชิก และคำเชิญ
+type Repository interface {
+	Create(ctx context.Context, o Organization, ownerID int) (Organization, error)
+	FindByID(ctx context.Context, id int) (Organization, error)
+	FindBySlug(ctx context.Context, slug string) (Organization, error)
+	ListForUser(ctx context.Context, userID int) ([]UserOrganization, error)
+	FindMembership(ctx context.Context, orgID, userID int) (Membership, error)
+	ListMembers(ctx context.Context, orgID int) ([]Member, error)
+	UpdateRole(ctx context.Context, orgID, userID int, role Role) error
+	CreateInvitation(ctx context.Context, inv Invitation) (Invitation, error)
+	FindInvitationByTokenHash(ctx context.Context, tokenHash string) (Invitation, error)
+	AcceptInvitation(ctx context.Context, invitationID, userID int) error
+	ListInvitationsByEmail(ctx context.Context, email string) ([]Invitation, error)
+	DeleteMemberships(ctx context.Context, userID int) error
+	DeleteInvitationsByEmail(ctx context.Context, email string) error
+	ClearInviter(ctx context.Context, userID int) error
+}
+
+// repo เป็น implementation ที่ใช้ pgxpool หรือ pgx.Tx
+// ถ้า ctx มี transaction จาก db.TxManager ทุก query จะรันใน transaction นั้นแทน
+type repo struct {
+	db db.DBTX
+}
+
+// NewRepository คืนค่า repository ที่พร้อมใช้งานกับฐานข้อมูล (ส่ง pool หรือ transaction ก็ได้)
+func NewRepository(conn db.DBTX) Repository {
+	return &repo{db: conn}
+}
+
+func (r *repo) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.db)
+}
+
+// Create สร้างองค์กรและตั้งผู้สร้างเป็น owner ใน transaction เดียวกัน
+func (r *repo) Create(ctx context.Context, o Organization, ownerID int) (Organization, error) {
+	const insertOrg = `
+		INSERT INTO organizations (name, slug)
+		VALUES ($1, $2)
+		RETURNING id, name, slug, created_at
+	`
+	const insertOwner = `
+		INSERT INTO memberships (org_id, user_id, role)
+		VALUES ($1, $2, $3)
+	`
+
+	var created Organization
+	err := pgx.BeginFunc(ctx, r.conn(ctx), func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, insertOrg, o.Name, o.Slug)
+		if err := row.Scan(&created.ID, &created.Name, &created.Slug, &created.CreatedAt); err != nil {
+			err = db.Translate(err)
+			if errors.Is(err, db.ErrUniqueViolation) {
+				return fmt.Errorf("insert organization: %w", ErrSlugInUse)
+			}
+			return fmt.Errorf("insert organization: %w", err)
+		}
+		if _, err := tx.Exec(ctx, insertOwner, created.ID, ownerID, RoleOwner); err != nil {
+			return fmt.Errorf("insert owner membership: %w", db.Translate(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return Organization{}, err
+	}
+	return created, nil
+}
+
+func (r *repo) FindByID(ctx context.Context, id int) (Organization, error) {
+	const query = `
+		SELECT id, name, slug, created_at
+		FROM organizations
+		WHERE id = $1
+	`
+
+	var o Organization
+	if err := r.conn(ctx).QueryRow(ctx, query, id).Scan(&o.ID, &o.Name, &o.Slug, &o.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Organization{}, fmt.Errorf("organization not found: %w", err)
+		}
+		return Organization{}, fmt.Errorf("scan organization: %w", err)
+	}
+	return o, nil
+}
+
+func (r *repo) FindBySlug(ctx context.Context, slug string) (Organization, error) {
+	const query = `
+		SELECT id, name, slug, created_at
+		FROM organizations
+		WHERE slug = $1
+	`
+
+	var o Organization
+	if err := r.conn(ctx).QueryRow(ctx, query, slug).Scan(&o.ID, &o.Name, &o.Slug, &o.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Organization{}, fmt.Errorf("organization not found: %w", err)
+		}
+		return Organization{}, fmt.Errorf("scan organization: %w", err)
+	}
+	return o, nil
+}
+
+func (r *repo) ListForUser(ctx context.Context, userID int) ([]UserOrganization, error) {
+	const query = `
+		SELECT o.id, o.name, o.slug, o.created_at, m.role
+		FROM organizations o
+		JOIN memberships m ON m.org_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []UserOrganization{}
+	for rows.Next() {
+		var o UserOrganization
+		if err := rows.Scan(&o.ID, &o.Name, &o.Slug, &o.CreatedAt, &o.Role); err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+		orgs = append(orgs, o)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate organizations: %w", rows.Err())
+	}
+	return orgs, nil
+}
+
+func (r *repo) FindMembership(ctx context.Context, orgID, userID int) (Membership, error) {
+	const query = `
+		SELECT org_id, user_id, role, created_at
+		FROM memberships
+		WHERE org_id = $1 AND user_id = $2
+	`
+
+	var m Membership
+	if err := r.conn(ctx).QueryRow(ctx, query, orgID, userID).Scan(&m.OrgID, &m.UserID, &m.Role, &m.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Membership{}, fmt.Errorf("membership not found: %w", err)
+		}
+		return Membership{}, fmt.Errorf("scan membership: %w", err)
+	}
+	return m, nil
+}
+
+func (r *repo) ListMembers(ctx context.Context, orgID int) ([]Member, error) {
+	const query = `
+		SELECT u.id, u.email, u.name, m.role, m.created_at
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = $1
+		ORDER BY m.created_at
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("query members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []Member{}
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Name, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate members: %w", rows.Err())
+	}
+	return members, nil
+}
+
+func (r *repo) UpdateRole(ctx context.Context, orgID, userID int, role Role) error {
+	const query = `
+		UPDATE memberships
+		SET role = $1
+		WHERE org_id = $2 AND user_id = $3
+	`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, role, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("update role: %w", db.Translate(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("membership not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *repo) CreateInvitation(ctx context.Context, inv Invitation) (Invitation, error) {
+	const query = `
+		INSERT INTO invitations (org_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	row := r.conn(ctx).QueryRow(ctx, query, inv.OrgID, inv.Email, inv.Role, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt)
+	if err := row.Scan(&inv.ID, &inv.CreatedAt); err != nil {
+		return Invitation{}, fmt.Errorf("insert invitation: %w", db.Translate(err))
+	}
+	return inv, nil
+}
+
+func (r *repo) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (Invitation, error) {
+	const query = `
+		SELECT id, org_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+		FROM invitations
+		WHERE token_hash = $1
+	`
+
+	inv, err := scanInvitation(r.conn(ctx).QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Invitation{}, fmt.Errorf("invitation not found: %w", err)
+		}
+		return Invitation{}, fmt.Errorf("scan invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// AcceptInvitation เพิ่มผู้ใช้เป็นสมาชิกและปิดคำเชิญใน transaction เดียวกัน
+// ถ้าเป็นสมาชิกอยู่แล้วจะไม่ลด/เพิ่ม role เดิม
+func (r *repo) AcceptInvitation(ctx context.Context, invitationID, userID int) error {
+	const markAccepted = `
+		UPDATE invitations
+		SET accepted_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL
+		RETURNING org_id, role
+	`
+	const insertMember = `
+		INSERT INTO memberships (org_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (org_id, user_id) DO NOTHING
+	`
+
+	return pgx.BeginFunc(ctx, r.conn(ctx), func(tx pgx.Tx) error {
+		var (
+			orgID int
+			role  Role
+		)
+		if err := tx.QueryRow(ctx, markAccepted, invitationID).Scan(&orgID, &role); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("invitation already accepted: %w", err)
+			}
+			return fmt.Errorf("accept invitation: %w", err)
+		}
+		if _, err := tx.Exec(ctx, insertMember, orgID, userID, role); err != nil {
+			return fmt.Errorf("insert membership: %w", db.Translate(err))
+		}
+		return nil
+	})
+}
+
+func (r *repo) ListInvitationsByEmail(ctx context.Context, email string) ([]Invitation, error) {
+	const query = `
+		SELECT id, org_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+		FROM invitations
+		WHERE email = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, email)
+	if err != nil {
+		return nil, fmt.Errorf("query invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate invitations: %w", rows.Err())
+	}
+	return invitations, nil
+}
+
+func (r *repo) DeleteMemberships(ctx context.Context, userID int) error {
+	const query = `
+		DELETE FROM memberships
+		WHERE user_id = $1
+	`
+
+	if _, err := r.conn(ctx).Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("delete memberships: %w", db.Translate(err))
+	}
+	return nil
+}
+
+func (r *repo) DeleteInvitationsByEmail(ctx context.Context, email string) error {
+	const query = `
+		DELETE FROM invitations
+		WHERE email = $1
+	`
+
+	if _, err := r.conn(ctx).Exec(ctx, query, email); err != nil {
+		return fmt.Errorf("delete invitations: %w", db.Translate(err))
+	}
+	return nil
+}
+
+// ClearInviter ตัดการอ้างอิงถึงผู้เชิญออกจากคำเชิญทั้งหมด (ใช้ตอนลบบัญชี)
+func (r *repo) ClearInviter(ctx context.Context, userID int) error {
+	const query = `
+		UPDATE invitations
+		SET invited_by = NULL
+		WHERE invited_by = $1
+	`
+
+	if _, err := r.conn(ctx).Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("clear inviter: %w", db.Translate(err))
+	}
+	return nil
+}
+
+func scanInvitation(row pgx.Row) (Invitation, error) {
+	var inv Invitation
+	err := row.Scan(&inv.ID, &inv.OrgID, &inv.Email, &inv.Role, &inv.TokenHash, &inv.InvitedBy, &inv.ExpiresAt, &inv.AcceptedAt, &inv.CreatedAt)
+	return inv, err
+}
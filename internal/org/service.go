@@ -0,0 +1,330 @@
+package org
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/audit"
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/i18n"
+	mailer "fristGoproject/internal/mail"
+	"fristGoproject/internal/user"
+)
+
+// invitationTTL คืออายุของลิงก์คำเชิญ
+const invitationTTL = 7 * 24 * time.Hour
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+var (
+	// ErrSlugInUse จะถูกส่งกลับเมื่อ slug ขององค์กรซ้ำกับที่มีอยู่
+	ErrSlugInUse = i18n.NewError(i18n.MsgSlugInUse)
+	// ErrNotMember จะถูกส่งกลับเมื่อผู้ใช้ไม่ได้เป็นสมาชิกขององค์กรที่ขอ
+	ErrNotMember = i18n.NewError(i18n.MsgNotMember)
+	// ErrTenantRequired จะถูกส่งกลับเมื่อผู้ใช้อยู่หลายองค์กรแต่ไม่ได้ระบุว่าจะใช้องค์กรไหน
+	ErrTenantRequired = i18n.NewError(i18n.MsgTenantRequired)
+	// ErrForbidden จะถูกส่งกลับเมื่อ role ไม่พอสำหรับการกระทำนั้น
+	ErrForbidden = i18n.NewError(i18n.MsgOrgForbidden)
+	// ErrInvalidInvitation จะถูกส่งกลับเมื่อ token ไม่ถูกต้อง หมดอายุ ใช้ไปแล้ว หรือไม่ได้เชิญอีเมลนี้
+	ErrInvalidInvitation = i18n.NewError(i18n.MsgInvalidInvitation)
+	// ErrInvalidInput ใช้กับข้อมูลที่ผู้ใช้ส่งมาไม่ผ่านการตรวจ
+	ErrInvalidInput = i18n.NewError(i18n.MsgInvalidInput)
+)
+
+// errInvalidRole คือรายละเอียดที่ห่อกับ ErrInvalidInput เมื่อ role ไม่ใช่ค่าที่รู้จัก
+var errInvalidRole = i18n.NewError(i18n.MsgFieldOneOf, "role", "owner, admin, member")
+
+// Service เก็บ business logic ของการจัดการองค์กร สมาชิก และคำเชิญ
+type Service struct {
+	orgs      Repository
+	users     user.Repository
+	tx        db.Transactor
+	mail      mailer.Sender
+	audit     audit.Recorder
+	acceptURL string
+	now       func() time.Time
+}
+
+// NewService คืน service พร้อมใช้งาน acceptURL คือ URL เต็มของ endpoint รับคำเชิญ
+// (เช่น https://example.com/v1/invitations/accept) ใช้สร้างลิงก์ในอีเมลโดยเติม ?token=
+// การเปลี่ยน role ของสมาชิกถูกบันทึกลง recorder
+func NewService(orgs Repository, users user.Repository, tx db.Transactor, sender mailer.Sender, recorder audit.Recorder, acceptURL string) *Service {
+	return &Service{
+		orgs:      orgs,
+		users:     users,
+		tx:        tx,
+		mail:      sender,
+		audit:     recorder,
+		acceptURL: acceptURL,
+		now:       time.Now,
+	}
+}
+
+// Create สร้างองค์กรใหม่และตั้งผู้สร้างเป็น owner
+func (s *Service) Create(ctx context.Context, ownerID int, name, slug string) (Organization, error) {
+	name = strings.TrimSpace(name)
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if name == "" {
+		return Organization{}, fmt.Errorf("%w: %w", ErrInvalidInput, i18n.NewError(i18n.MsgFieldRequired, "name"))
+	}
+	if !slugPattern.MatchString(slug) {
+		return Organization{}, fmt.Errorf("%w: %w", ErrInvalidInput, i18n.NewError(i18n.MsgSlugFormat))
+	}
+
+	if _, err := s.orgs.FindBySlug(ctx, slug); err == nil {
+		return Organization{}, ErrSlugInUse
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return Organization{}, fmt.Errorf("ตรวจสอบ slug ซ้ำ: %w", err)
+	}
+
+	created, err := s.orgs.Create(ctx, Organization{Name: name, Slug: slug}, ownerID)
+	if err != nil {
+		return Organization{}, fmt.Errorf("สร้างองค์กร: %w", err)
+	}
+	return created, nil
+}
+
+// ListForUser คืนองค์กรทั้งหมดที่ผู้ใช้เป็นสมาชิก
+func (s *Service) ListForUser(ctx context.Context, userID int) ([]UserOrganization, error) {
+	orgs, err := s.orgs.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ดึงรายชื่อองค์กร: %w", err)
+	}
+	return orgs, nil
+}
+
+// ResolveTenant หาองค์กรที่ใช้กับคำขอนี้
+// ถ้า requestedOrgID > 0 จะตรวจว่าเป็นสมาชิกจริง ถ้าไม่ระบุและผู้ใช้อยู่องค์กรเดียวจะใช้องค์กรนั้น
+func (s *Service) ResolveTenant(ctx context.Context, userID, requestedOrgID int) (Membership, error) {
+	if requestedOrgID > 0 {
+		m, err := s.orgs.FindMembership(ctx, requestedOrgID, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return Membership{}, ErrNotMember
+			}
+			return Membership{}, fmt.Errorf("ตรวจสอบสมาชิก: %w", err)
+		}
+		return m, nil
+	}
+
+	orgs, err := s.orgs.ListForUser(ctx, userID)
+	if err != nil {
+		return Membership{}, fmt.Errorf("ดึงรายชื่อองค์กร: %w", err)
+	}
+	switch len(orgs) {
+	case 0:
+		return Membership{}, ErrNotMember
+	case 1:
+		return Membership{OrgID: orgs[0].ID, UserID: userID, Role: orgs[0].Role}, nil
+	default:
+		return Membership{}, ErrTenantRequired
+	}
+}
+
+// Members คืนสมาชิกทั้งหมดขององค์กร (ผู้เรียกต้องผ่าน ResolveTenant มาแล้ว)
+func (s *Service) Members(ctx context.Context, orgID int) ([]Member, error) {
+	members, err := s.orgs.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("ดึงรายชื่อสมาชิก: %w", err)
+	}
+	return members, nil
+}
+
+// ChangeRole เปลี่ยน role ของสมาชิก ผู้เรียกต้องเป็น owner/admin
+// เฉพาะ owner ที่ให้หรือถอด role owner ได้ และห้ามเปลี่ยน role ของตัวเอง
+func (s *Service) ChangeRole(ctx context.Context, actor Membership, targetUserID int, role Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, errInvalidRole)
+	}
+	if !actor.Role.CanManage() || actor.UserID == targetUserID {
+		return ErrForbidden
+	}
+
+	target, err := s.orgs.FindMembership(ctx, actor.OrgID, targetUserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotMember
+		}
+		return fmt.Errorf("ค้นหาสมาชิก: %w", err)
+	}
+	if (role == RoleOwner || target.Role == RoleOwner) && actor.Role != RoleOwner {
+		return ErrForbidden
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.orgs.UpdateRole(ctx, actor.OrgID, targetUserID, role); err != nil {
+			return fmt.Errorf("เปลี่ยน role: %w", err)
+		}
+		return s.audit.Record(ctx, audit.Entry{
+			Action:    audit.RoleChanged,
+			ActorType: audit.ActorUser,
+			ActorID:   &actor.UserID,
+			Target:    audit.UserTarget(targetUserID),
+			Details:   map[string]any{"org_id": actor.OrgID, "from": target.Role, "to": role},
+		})
+	})
+}
+
+// Invite สร้างคำเชิญและส่งอีเมลพร้อมลิงก์รับคำเชิญ
+// token จริงอยู่แค่ในอีเมล ฐานข้อมูลเก็บเฉพาะ SHA-256 ของ token
+func (s *Service) Invite(ctx context.Context, actor Membership, email string, role Role) (Invitation, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return Invitation{}, fmt.Errorf("%w: %w", ErrInvalidInput, i18n.NewError(i18n.MsgFieldEmail, "email"))
+	}
+	if role == "" {
+		role = RoleMember
+	}
+	if !role.Valid() {
+		return Invitation{}, fmt.Errorf("%w: %w", ErrInvalidInput, errInvalidRole)
+	}
+	if !actor.Role.CanManage() || (role == RoleOwner && actor.Role != RoleOwner) {
+		return Invitation{}, ErrForbidden
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return Invitation{}, fmt.Errorf("สร้าง token: %w", err)
+	}
+
+	// บันทึกคำเชิญให้ commit ก่อนแล้วค่อยส่งอีเมล ไม่อย่างนั้นถ้า rollback หลังส่ง ผู้รับจะได้ token ที่ไม่มีอยู่จริง
+	// ถ้าส่งอีเมลไม่สำเร็จ คำเชิญที่ค้างอยู่ไม่มีใครได้ token และจะหมดอายุเอง ผู้เชิญส่งใหม่ได้
+	inviter := actor.UserID
+	inv, err := s.orgs.CreateInvitation(ctx, Invitation{
+		OrgID:     actor.OrgID,
+		Email:     email,
+		Role:      role,
+		TokenHash: hashToken(token),
+		InvitedBy: &inviter,
+		ExpiresAt: s.now().Add(invitationTTL),
+	})
+	if err != nil {
+		return Invitation{}, fmt.Errorf("บันทึกคำเชิญ: %w", err)
+	}
+
+	if err := s.sendInvitation(ctx, actor, inv, token); err != nil {
+		return Invitation{}, fmt.Errorf("ส่งอีเมลคำเชิญ: %w", err)
+	}
+	return inv, nil
+}
+
+func (s *Service) sendInvitation(ctx context.Context, actor Membership, inv Invitation, token string) error {
+	inviter, err := s.users.FindByID(ctx, actor.UserID)
+	if err != nil {
+		return fmt.Errorf("ค้นหาผู้เชิญ: %w", err)
+	}
+	organization, err := s.orgs.FindByID(ctx, inv.OrgID)
+	if err != nil {
+		return fmt.Errorf("ค้นหาองค์กร: %w", err)
+	}
+
+	msg, err := renderInvitation(i18n.FromContext(ctx), invitationData{
+		OrgName:     organization.Name,
+		InviterName: inviter.Name,
+		Role:        inv.Role,
+		AcceptURL:   s.acceptURL + "?token=" + url.QueryEscape(token),
+		ExpiresAt:   inv.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	msg.To = inv.Email
+	return s.mail.Send(ctx, msg)
+}
+
+// Accept ใช้ token จากอีเมลเพื่อเข้าร่วมองค์กร อีเมลของผู้ใช้ต้องตรงกับอีเมลที่ถูกเชิญ
+func (s *Service) Accept(ctx context.Context, u user.User, token string) (Invitation, error) {
+	var inv Invitation
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.orgs.FindInvitationByTokenHash(ctx, hashToken(strings.TrimSpace(token)))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvalidInvitation
+			}
+			return fmt.Errorf("ค้นหาคำเชิญ: %w", err)
+		}
+		if inv.AcceptedAt != nil || s.now().After(inv.ExpiresAt) || !strings.EqualFold(inv.Email, u.Email) {
+			return ErrInvalidInvitation
+		}
+
+		if err := s.orgs.AcceptInvitation(ctx, inv.ID, u.ID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvalidInvitation
+			}
+			return fmt.Errorf("รับคำเชิญ: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return Invitation{}, err
+	}
+	now := s.now()
+	inv.AcceptedAt = &now
+	return inv, nil
+}
+
+// Name ใช้เป็นชื่อ section ใน user export (implement user.DataSource)
+func (s *Service) Name() string {
+	return "organizations"
+}
+
+// Export คืนองค์กรที่ผู้ใช้เป็นสมาชิกและคำเชิญที่ส่งถึงอีเมลของผู้ใช้ (implement user.DataSource)
+func (s *Service) Export(ctx context.Context, userID int) (any, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ค้นหาผู้ใช้: %w", err)
+	}
+	memberships, err := s.orgs.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ดึงรายชื่อองค์กร: %w", err)
+	}
+	invitations, err := s.orgs.ListInvitationsByEmail(ctx, u.Email)
+	if err != nil {
+		return nil, fmt.Errorf("ดึงคำเชิญ: %w", err)
+	}
+	return map[string]any{
+		"memberships": memberships,
+		"invitations": invitations,
+	}, nil
+}
+
+// Erase ลบสมาชิกภาพและคำเชิญถึงผู้ใช้ และตัดชื่อผู้ใช้ออกจากคำเชิญที่เคยส่ง (implement user.DataSource)
+func (s *Service) Erase(ctx context.Context, userID int) error {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("ค้นหาผู้ใช้: %w", err)
+	}
+	if err := s.orgs.ClearInviter(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.orgs.DeleteInvitationsByEmail(ctx, u.Email); err != nil {
+		return err
+	}
+	return s.orgs.DeleteMemberships(ctx, userID)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,377 @@
+package org
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/db/sqlite"
+)
+
+// sqliteRepo เป็น implementation บน SQLite ใช้ฐานเดียวกับ user.NewSQLiteRepository
+// หาไม่เจอคืน error ที่ห่อ pgx.ErrNoRows เหมือนฝั่ง Postgres
+type sqliteRepo struct {
+	db  *sql.DB
+	tx  *sqlite.TxManager
+	now func() time.Time
+}
+
+// NewSQLiteRepository คืน repository ที่ใช้ฐาน SQLite จาก sqlite.Open
+func NewSQLiteRepository(sqldb *sql.DB) Repository {
+	return &sqliteRepo{db: sqldb, tx: sqlite.NewTxManager(sqldb), now: time.Now}
+}
+
+func (r *sqliteRepo) conn(ctx context.Context) sqlite.DBTX {
+	return sqlite.Conn(ctx, r.db)
+}
+
+// Create สร้างองค์กรและตั้งผู้สร้างเป็น owner ใน transaction เดียวกัน
+func (r *sqliteRepo) Create(ctx context.Context, o Organization, ownerID int) (Organization, error) {
+	const insertOrg = `
+		INSERT INTO organizations (name, slug, created_at)
+		VALUES (?, ?, ?)
+		RETURNING id
+	`
+	const insertOwner = `
+		INSERT INTO memberships (org_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	created := Organization{Name: o.Name, Slug: o.Slug, CreatedAt: r.now().UTC().Truncate(time.Microsecond)}
+	createdAt := sqlite.FormatTime(created.CreatedAt)
+	err := r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		if err := tx.QueryRowContext(ctx, insertOrg, o.Name, o.Slug, createdAt).Scan(&created.ID); err != nil {
+			err = sqlite.Translate(err)
+			if errors.Is(err, db.ErrUniqueViolation) {
+				return fmt.Errorf("insert organization: %w", ErrSlugInUse)
+			}
+			return fmt.Errorf("insert organization: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, insertOwner, created.ID, ownerID, RoleOwner, createdAt); err != nil {
+			return fmt.Errorf("insert owner membership: %w", sqlite.Translate(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return Organization{}, err
+	}
+	return created, nil
+}
+
+func (r *sqliteRepo) FindByID(ctx context.Context, id int) (Organization, error) {
+	const query = `
+		SELECT id, name, slug, created_at
+		FROM organizations
+		WHERE id = ?
+	`
+	return r.findOrganization(ctx, query, id)
+}
+
+func (r *sqliteRepo) FindBySlug(ctx context.Context, slug string) (Organization, error) {
+	const query = `
+		SELECT id, name, slug, created_at
+		FROM organizations
+		WHERE slug = ?
+	`
+	return r.findOrganization(ctx, query, slug)
+}
+
+func (r *sqliteRepo) findOrganization(ctx context.Context, query string, arg any) (Organization, error) {
+	var (
+		o         Organization
+		createdAt string
+	)
+	if err := r.conn(ctx).QueryRowContext(ctx, query, arg).Scan(&o.ID, &o.Name, &o.Slug, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Organization{}, fmt.Errorf("organization not found: %w", pgx.ErrNoRows)
+		}
+		return Organization{}, fmt.Errorf("scan organization: %w", err)
+	}
+	t, err := sqlite.ParseTime(createdAt)
+	if err != nil {
+		return Organization{}, fmt.Errorf("scan organization: %w", err)
+	}
+	o.CreatedAt = t
+	return o, nil
+}
+
+func (r *sqliteRepo) ListForUser(ctx context.Context, userID int) ([]UserOrganization, error) {
+	const query = `
+		SELECT o.id, o.name, o.slug, o.created_at, m.role
+		FROM organizations o
+		JOIN memberships m ON m.org_id = o.id
+		WHERE m.user_id = ?
+		ORDER BY o.name
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []UserOrganization{}
+	for rows.Next() {
+		var (
+			o         UserOrganization
+			createdAt string
+		)
+		if err := rows.Scan(&o.ID, &o.Name, &o.Slug, &createdAt, &o.Role); err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+		if o.CreatedAt, err = sqlite.ParseTime(createdAt); err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+		orgs = append(orgs, o)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate organizations: %w", rows.Err())
+	}
+	return orgs, nil
+}
+
+func (r *sqliteRepo) FindMembership(ctx context.Context, orgID, userID int) (Membership, error) {
+	const query = `
+		SELECT org_id, user_id, role, created_at
+		FROM memberships
+		WHERE org_id = ? AND user_id = ?
+	`
+
+	var (
+		m         Membership
+		createdAt string
+	)
+	if err := r.conn(ctx).QueryRowContext(ctx, query, orgID, userID).Scan(&m.OrgID, &m.UserID, &m.Role, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Membership{}, fmt.Errorf("membership not found: %w", pgx.ErrNoRows)
+		}
+		return Membership{}, fmt.Errorf("scan membership: %w", err)
+	}
+	t, err := sqlite.ParseTime(createdAt)
+	if err != nil {
+		return Membership{}, fmt.Errorf("scan membership: %w", err)
+	}
+	m.CreatedAt = t
+	return m, nil
+}
+
+func (r *sqliteRepo) ListMembers(ctx context.Context, orgID int) ([]Member, error) {
+	const query = `
+		SELECT u.id, u.email, u.name, m.role, m.created_at
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = ?
+		ORDER BY m.created_at
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("query members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []Member{}
+	for rows.Next() {
+		var (
+			m        Member
+			joinedAt string
+		)
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Name, &m.Role, &joinedAt); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		if m.JoinedAt, err = sqlite.ParseTime(joinedAt); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate members: %w", rows.Err())
+	}
+	return members, nil
+}
+
+func (r *sqliteRepo) UpdateRole(ctx context.Context, orgID, userID int, role Role) error {
+	const query = `
+		UPDATE memberships
+		SET role = ?
+		WHERE org_id = ? AND user_id = ?
+	`
+
+	res, err := r.conn(ctx).ExecContext(ctx, query, role, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("update role: %w", sqlite.Translate(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update role: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("membership not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *sqliteRepo) CreateInvitation(ctx context.Context, inv Invitation) (Invitation, error) {
+	const query = `
+		INSERT INTO invitations (org_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	inv.CreatedAt = r.now().UTC().Truncate(time.Microsecond)
+	row := r.conn(ctx).QueryRowContext(ctx, query, inv.OrgID, inv.Email, inv.Role, inv.TokenHash, inv.InvitedBy,
+		sqlite.FormatTime(inv.ExpiresAt), sqlite.FormatTime(inv.CreatedAt))
+	if err := row.Scan(&inv.ID); err != nil {
+		return Invitation{}, fmt.Errorf("insert invitation: %w", sqlite.Translate(err))
+	}
+	return inv, nil
+}
+
+func (r *sqliteRepo) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (Invitation, error) {
+	const query = `
+		SELECT id, org_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+		FROM invitations
+		WHERE token_hash = ?
+	`
+
+	inv, err := scanSQLiteInvitation(r.conn(ctx).QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Invitation{}, fmt.Errorf("invitation not found: %w", pgx.ErrNoRows)
+		}
+		return Invitation{}, fmt.Errorf("scan invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// AcceptInvitation เพิ่มผู้ใช้เป็นสมาชิกและปิดคำเชิญใน transaction เดียวกัน
+// ถ้าเป็นสมาชิกอยู่แล้วจะไม่ลด/เพิ่ม role เดิม
+func (r *sqliteRepo) AcceptInvitation(ctx context.Context, invitationID, userID int) error {
+	const markAccepted = `
+		UPDATE invitations
+		SET accepted_at = ?
+		WHERE id = ? AND accepted_at IS NULL
+		RETURNING org_id, role
+	`
+	const insertMember = `
+		INSERT INTO memberships (org_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (org_id, user_id) DO NOTHING
+	`
+
+	now := sqlite.FormatTime(r.now())
+	return r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		var (
+			orgID int
+			role  Role
+		)
+		if err := tx.QueryRowContext(ctx, markAccepted, now, invitationID).Scan(&orgID, &role); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("invitation already accepted: %w", pgx.ErrNoRows)
+			}
+			return fmt.Errorf("accept invitation: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, insertMember, orgID, userID, role, now); err != nil {
+			return fmt.Errorf("insert membership: %w", sqlite.Translate(err))
+		}
+		return nil
+	})
+}
+
+func (r *sqliteRepo) ListInvitationsByEmail(ctx context.Context, email string) ([]Invitation, error) {
+	const query = `
+		SELECT id, org_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+		FROM invitations
+		WHERE email = ?
+		ORDER BY created_at
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, email)
+	if err != nil {
+		return nil, fmt.Errorf("query invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []Invitation{}
+	for rows.Next() {
+		inv, err := scanSQLiteInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate invitations: %w", rows.Err())
+	}
+	return invitations, nil
+}
+
+func (r *sqliteRepo) DeleteMemberships(ctx context.Context, userID int) error {
+	const query = `DELETE FROM memberships WHERE user_id = ?`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("delete memberships: %w", sqlite.Translate(err))
+	}
+	return nil
+}
+
+func (r *sqliteRepo) DeleteInvitationsByEmail(ctx context.Context, email string) error {
+	const query = `DELETE FROM invitations WHERE email = ?`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, email); err != nil {
+		return fmt.Errorf("delete invitations: %w", sqlite.Translate(err))
+	}
+	return nil
+}
+
+// ClearInviter ตัดการอ้างอิงถึงผู้เชิญออกจากคำเชิญทั้งหมด (ใช้ตอนลบบัญชี)
+func (r *sqliteRepo) ClearInviter(ctx context.Context, userID int) error {
+	const query = `UPDATE invitations SET invited_by = NULL WHERE invited_by = ?`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("clear inviter: %w", sqlite.Translate(err))
+	}
+	return nil
+}
+
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSQLiteInvitation(row sqliteScanner) (Invitation, error) {
+	var (
+		inv        Invitation
+		invitedBy  sql.NullInt64
+		expiresAt  string
+		acceptedAt sql.NullString
+		createdAt  string
+	)
+	err := row.Scan(&inv.ID, &inv.OrgID, &inv.Email, &inv.Role, &inv.TokenHash, &invitedBy, &expiresAt, &acceptedAt, &createdAt)
+	if err != nil {
+		return Invitation{}, err
+	}
+	if invitedBy.Valid {
+		id := int(invitedBy.Int64)
+		inv.InvitedBy = &id
+	}
+	if inv.ExpiresAt, err = sqlite.ParseTime(expiresAt); err != nil {
+		return Invitation{}, err
+	}
+	if inv.AcceptedAt, err = sqlite.ParseNullTime(acceptedAt); err != nil {
+		return Invitation{}, err
+	}
+	if inv.CreatedAt, err = sqlite.ParseTime(createdAt); err != nil {
+		return Invitation{}, err
+	}
+	return inv, nil
+}
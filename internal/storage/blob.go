@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNotFound จะถูกส่งกลับเมื่อไม่มีไฟล์ตาม key ที่ขอ
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore คือที่เก็บไฟล์ (รูปภาพ, เอกสาร) แบบ key/value
+// key ใช้ "/" คั่นเหมือน path เช่น "avatars/42/256.jpg"
+type BlobStore interface {
+	// Put เขียนไฟล์ทับ key เดิมถ้ามีอยู่แล้ว
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete ลบไฟล์ ถ้าไม่มีไฟล์อยู่แล้วถือว่าสำเร็จ
+	Delete(ctx context.Context, key string) error
+	// URL คืน URL สาธารณะที่ client ใช้ดาวน์โหลดไฟล์ได้
+	URL(key string) string
+}
+
+// NewFromEnv เลือก driver ตาม BLOB_DRIVER (local หรือ s3) แล้วอ่านค่าที่เกี่ยวข้องจาก env
+func NewFromEnv() (BlobStore, error) {
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("BLOB_DRIVER")))
+	switch driver {
+	case "", "local":
+		dir := os.Getenv("BLOB_LOCAL_DIR")
+		if dir == "" {
+			dir = "data/media"
+		}
+		publicURL := os.Getenv("BLOB_PUBLIC_URL")
+		if publicURL == "" {
+			publicURL = "/media"
+		}
+		return NewLocalStore(dir, publicURL)
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+		})
+	default:
+		return nil, fmt.Errorf("ไม่รู้จัก BLOB_DRIVER %q", driver)
+	}
+}
+
+// cleanKey กัน key ที่พยายามออกนอก root เช่น "../etc/passwd"
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		return "", errors.New("key ต้องไม่ว่าง")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("key ไม่ถูกต้อง: %q", key)
+		}
+	}
+	return key, nil
+}
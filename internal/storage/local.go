@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore เก็บไฟล์ลงดิสก์ของเครื่อง เหมาะกับ dev หรือ deployment เครื่องเดียว
+// ไฟล์ถูกเสิร์ฟผ่าน Router.ServeMedia ที่ชี้ไปยัง dir เดียวกัน
+type LocalStore struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalStore สร้างโฟลเดอร์ dir (ถ้ายังไม่มี) และคืน store ที่สร้าง URL จาก publicURL
+func NewLocalStore(dir, publicURL string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("สร้างโฟลเดอร์ %s: %w", dir, err)
+	}
+	return &LocalStore{dir: dir, publicURL: strings.TrimSuffix(publicURL, "/")}, nil
+}
+
+// Dir คืนโฟลเดอร์ที่เก็บไฟล์ ใช้ตอนเปิดเสิร์ฟไฟล์แบบ static
+func (s *LocalStore) Dir() string {
+	return s.dir
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("สร้างโฟลเดอร์: %w", err)
+	}
+
+	// เขียนลงไฟล์ชั่วคราวก่อนแล้ว rename เพื่อไม่ให้ client อ่านเจอไฟล์ครึ่ง ๆ กลาง ๆ
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("สร้างไฟล์ชั่วคราว: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("เขียนไฟล์: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ปิดไฟล์: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("ตั้งสิทธิ์ไฟล์: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("ย้ายไฟล์: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("ลบไฟล์: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) URL(key string) string {
+	return s.publicURL + "/" + strings.TrimPrefix(key, "/")
+}
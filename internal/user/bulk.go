@@ -0,0 +1,325 @@
+package user
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/event"
+	"fristGoproject/internal/i18n"
+	"fristGoproject/pkg/password"
+)
+
+// DuplicatePolicy กำหนดว่าจะทำอย่างไรเมื่อแถวที่นำเข้ามีอีเมลซ้ำกับผู้ใช้ที่มีอยู่
+type DuplicatePolicy string
+
+const (
+	// DuplicateSkip ข้ามแถวที่อีเมลซ้ำ (ค่า default)
+	DuplicateSkip DuplicatePolicy = "skip"
+	// DuplicateUpdate เขียนทับชื่อ, attributes และรหัสผ่าน (ถ้ามี) ของผู้ใช้เดิม
+	DuplicateUpdate DuplicatePolicy = "update"
+	// DuplicateFail ถือว่าแถวที่ซ้ำเป็น error
+	DuplicateFail DuplicatePolicy = "fail"
+)
+
+// ParseDuplicatePolicy แปลงข้อความ (เช่นจาก query string) เป็น DuplicatePolicy
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch p := DuplicatePolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return DuplicateSkip, nil
+	case DuplicateSkip, DuplicateUpdate, DuplicateFail:
+		return p, nil
+	default:
+		return "", fmt.Errorf("on_duplicate ต้องเป็น skip, update หรือ fail (ได้ %q)", s)
+	}
+}
+
+// ImportRow คือผู้ใช้หนึ่งคนจากไฟล์นำเข้า
+// ต้องมี Password (SHA-256 hex แบบเดียวกับ API) หรือ PasswordHash (hash เดิมจากระบบเก่า) อย่างใดอย่างหนึ่ง
+type ImportRow struct {
+	Line         int
+	Email        string
+	Name         string
+	Password     string
+	PasswordHash string
+	Attributes   map[string]any
+}
+
+// RowError บอกว่าแถวไหนนำเข้าไม่ได้เพราะอะไร
+// Message เป็นภาษา Default จนกว่า Import จะแปลเป็นภาษาของ ctx
+type RowError struct {
+	Line    int    `json:"line"`
+	Email   string `json:"email,omitempty"`
+	Message string `json:"message"`
+	detail  *i18n.Error
+}
+
+// newRowError คืน RowError ของข้อความ id ใน catalog
+func newRowError(line int, email string, id i18n.Message, args ...any) *RowError {
+	detail := i18n.NewError(id, args...)
+	return &RowError{Line: line, Email: email, Message: detail.Error(), detail: detail}
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("แถว %d: %s", e.Line, e.Message)
+}
+
+// Localize คืนสำเนาที่ Message เป็นภาษา lang
+func (e RowError) Localize(lang i18n.Lang) RowError {
+	if e.detail != nil {
+		e.Message = e.detail.Localize(lang)
+	}
+	return e
+}
+
+// RowReader อ่านแถวจากไฟล์นำเข้าทีละแถว คืน io.EOF เมื่อหมด
+// ถ้าแถวนั้นอ่านไม่ได้ให้คืน *RowError แล้วอ่านแถวถัดไปต่อได้
+type RowReader interface {
+	Next() (ImportRow, error)
+}
+
+// RowWriter เขียนผู้ใช้ออกเป็นไฟล์ export ทีละแถว
+type RowWriter interface {
+	Write(u User) error
+	Flush() error
+}
+
+// ImportOptions กำหนดพฤติกรรมของการนำเข้า
+type ImportOptions struct {
+	// DryRun ตรวจทุกแถวแต่ไม่เขียนลงฐานข้อมูล
+	DryRun      bool
+	OnDuplicate DuplicatePolicy
+}
+
+// ImportResult สรุปผลการนำเข้า
+type ImportResult struct {
+	DryRun  bool       `json:"dry_run"`
+	Total   int        `json:"total"`
+	Created int        `json:"created"`
+	Updated int        `json:"updated"`
+	Skipped int        `json:"skipped"`
+	Failed  int        `json:"failed"`
+	Errors  []RowError `json:"errors"`
+}
+
+// Import นำเข้าผู้ใช้ทีละแถวจาก src แถวที่ผิดจะถูกบันทึกใน ImportResult.Errors แล้วทำแถวต่อไป
+// error ที่คืนจากฟังก์ชันนี้หมายถึงการนำเข้าหยุดกลางคัน (เช่นฐานข้อมูลล่ม)
+func (s *Service) Import(ctx context.Context, src RowReader, opts ImportOptions) (ImportResult, error) {
+	if opts.OnDuplicate == "" {
+		opts.OnDuplicate = DuplicateSkip
+	}
+	// การตรวจอีเมลซ้ำต้องเห็นแถวที่เพิ่งสร้าง จึงอ่านจาก primary
+	ctx = db.WithPrimary(ctx)
+	lang := i18n.FromContext(ctx)
+	result := ImportResult{DryRun: opts.DryRun, Errors: []RowError{}}
+	seen := make(map[string]int)
+
+	for {
+		row, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		result.Total++
+
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			result.Failed++
+			result.Errors = append(result.Errors, rowErr.Localize(lang))
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("อ่านไฟล์นำเข้า: %w", err)
+		}
+
+		outcome, err := s.importRow(ctx, row, opts, seen)
+		if errors.As(err, &rowErr) {
+			result.Failed++
+			result.Errors = append(result.Errors, rowErr.Localize(lang))
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		switch outcome {
+		case importCreated:
+			result.Created++
+		case importUpdated:
+			result.Updated++
+		case importSkipped:
+			result.Skipped++
+		}
+	}
+}
+
+type importOutcome int
+
+const (
+	importCreated importOutcome = iota
+	importUpdated
+	importSkipped
+)
+
+func (s *Service) importRow(ctx context.Context, row ImportRow, opts ImportOptions, seen map[string]int) (outcome importOutcome, err error) {
+	fail := func(id i18n.Message, args ...any) (importOutcome, error) {
+		return 0, newRowError(row.Line, row.Email, id, args...)
+	}
+
+	row.Email = strings.ToLower(strings.TrimSpace(row.Email))
+	row.Name = strings.TrimSpace(row.Name)
+	if row.Email == "" || row.Name == "" {
+		return fail(i18n.MsgImportRequired)
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		return fail(i18n.MsgImportEmail)
+	}
+
+	if first, ok := seen[row.Email]; ok {
+		return fail(i18n.MsgImportDuplicateRow, first)
+	}
+	// จองอีเมลเฉพาะแถวที่ผ่านทุกขั้น แถวที่ผิดจะได้ไม่กันแถวที่แก้แล้วซึ่งอยู่ถัดไปในไฟล์
+	defer func() {
+		if err == nil {
+			seen[row.Email] = row.Line
+		}
+	}()
+
+	hash, detail := importHash(row)
+	if detail != nil {
+		return fail(detail.ID, detail.Args...)
+	}
+
+	if s.validator != nil {
+		attrs := row.Attributes
+		if attrs == nil {
+			attrs = map[string]any{}
+		}
+		if err := s.validator.Validate(attrs); err != nil {
+			return fail(i18n.MsgImportSchema, err)
+		}
+	}
+
+	existing, err := s.repo.FindByEmail(ctx, row.Email)
+	switch {
+	case err == nil:
+		return s.importDuplicate(ctx, existing, row, hash, opts, fail)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return 0, fmt.Errorf("ตรวจสอบอีเมลซ้ำ: %w", err)
+	}
+
+	if opts.DryRun {
+		return importCreated, nil
+	}
+	if hash == "" {
+		hash, err = password.HashPassword(ctx, row.Password)
+		if err != nil {
+			return 0, fmt.Errorf("hash password: %w", err)
+		}
+	}
+	newUser := User{Email: row.Email, Name: row.Name, PasswordHash: hash, Attributes: row.Attributes}
+	var created User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if created, err = s.repo.Create(ctx, newUser); err != nil {
+			return err
+		}
+		data := RegisteredEvent{Email: created.Email, Name: created.Name, Source: "import"}
+		return s.events.Publish(ctx, event.New(event.UserRegistered, created.ID, data))
+	})
+	if err != nil {
+		if errors.Is(err, ErrDuplicateEmail) {
+			// มีคนสร้างอีเมลนี้ระหว่างที่กำลังนำเข้า
+			return fail(i18n.MsgEmailInUse)
+		}
+		// error จากฐานหรือ outbox หยุดการนำเข้าเหมือน importDuplicate ไม่ส่งข้อความภายในไปในผลรายแถว
+		return 0, fmt.Errorf("สร้างผู้ใช้: %w", err)
+	}
+	return importCreated, nil
+}
+
+func (s *Service) importDuplicate(ctx context.Context, existing User, row ImportRow, hash string, opts ImportOptions, fail func(i18n.Message, ...any) (importOutcome, error)) (importOutcome, error) {
+	switch opts.OnDuplicate {
+	case DuplicateFail:
+		return fail(i18n.MsgEmailInUse)
+	case DuplicateSkip:
+		return importSkipped, nil
+	}
+
+	if opts.DryRun {
+		return importUpdated, nil
+	}
+
+	if hash == "" {
+		var err error
+		hash, err = password.HashPassword(ctx, row.Password)
+		if err != nil {
+			return 0, fmt.Errorf("hash password: %w", err)
+		}
+	}
+
+	existing.Name = row.Name
+	if row.Attributes != nil {
+		existing.Attributes = row.Attributes
+	}
+	// โปรไฟล์กับรหัสผ่านต้องถูกอัปเดตพร้อมกัน ไม่ให้เหลือผู้ใช้ที่อัปเดตไปครึ่งเดียว
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateProfile(ctx, existing); err != nil {
+			return fmt.Errorf("อัปเดตผู้ใช้: %w", err)
+		}
+		if err := s.repo.UpdatePassword(ctx, existing.ID, hash); err != nil {
+			return fmt.Errorf("อัปเดตรหัสผ่าน: %w", err)
+		}
+		return s.events.Publish(ctx, profileUpdated(existing), event.New(event.UserPasswordChanged, existing.ID, nil))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return importUpdated, nil
+}
+
+// importHash คืน hash ที่จะเก็บ ถ้าแถวส่ง hash เดิมมาจะตรวจว่าเป็นรูปแบบที่ระบบรู้จัก
+// ถ้าส่ง password (SHA-256 hex) มาจะคืนค่าว่าง ให้ผู้เรียก hash เองตอนเขียนจริง (dry-run จะได้ไม่เสียเวลา Argon2)
+func importHash(row ImportRow) (string, *i18n.Error) {
+	rawPassword := strings.ToLower(strings.TrimSpace(row.Password))
+	preHashed := strings.TrimSpace(row.PasswordHash)
+
+	switch {
+	case rawPassword != "" && preHashed != "":
+		return "", i18n.NewError(i18n.MsgImportPasswordBoth)
+	case preHashed != "":
+		// รับเฉพาะรูปแบบที่ password.CheckPassword ตรวจได้ ไม่อย่างนั้นผู้ใช้ที่ย้ายมาจะเข้าสู่ระบบไม่ได้ตลอดไป
+		// hash ที่ไม่ใช่ argon2id จะถูก hash ใหม่ตอนผู้ใช้ล็อกอินครั้งแรก
+		// ข้อควรระวัง: CheckPassword ตรวจกับ sha256_hex(password) ที่ client ส่งมา ไม่ใช่รหัสผ่านจริง
+		// hash ของระบบเดิมต้องคำนวณจาก sha256_hex(password) ด้วย ถ้าคำนวณจาก plain text จะผ่านตรงนี้แต่ล็อกอินไม่ได้
+		// (ดูจาก hash อย่างเดียวแยกไม่ออก จึงต้องให้ผู้นำเข้ารับผิดชอบตามที่ README ระบุ)
+		if _, ok := password.Identify(preHashed); !ok {
+			return "", i18n.NewError(i18n.MsgImportHashUnknown)
+		}
+		return preHashed, nil
+	case rawPassword != "":
+		if _, err := hex.DecodeString(rawPassword); err != nil || len(rawPassword) != 64 {
+			return "", i18n.NewError(i18n.MsgImportPasswordFormat)
+		}
+		return "", nil
+	default:
+		return "", i18n.NewError(i18n.MsgImportPasswordMissing)
+	}
+}
+
+// ExportAll เขียนผู้ใช้ทั้งหมดลง dst แบบ streaming ทีละแถว
+func (s *Service) ExportAll(ctx context.Context, dst RowWriter) error {
+	err := s.repo.Stream(ctx, func(u User) error {
+		return dst.Write(u)
+	})
+	if err != nil {
+		return fmt.Errorf("export ผู้ใช้: %w", err)
+	}
+	return dst.Flush()
+}
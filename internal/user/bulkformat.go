@@ -0,0 +1,275 @@
+package user
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"fristGoproject/internal/i18n"
+)
+
+// attrColumnPrefix คือ prefix ของคอลัมน์ CSV ที่จะถูกเก็บเป็น custom attribute เช่น attr.department
+const attrColumnPrefix = "attr."
+
+// maxJSONLLine จำกัดความยาวต่อบรรทัดของไฟล์ JSON Lines
+const maxJSONLLine = 1 << 20
+
+// NewRowReader เลือก reader ตามรูปแบบไฟล์ ("csv" หรือ "jsonl")
+func NewRowReader(format string, r io.Reader) (RowReader, error) {
+	switch format {
+	case "csv":
+		return NewCSVRowReader(r)
+	case "jsonl", "ndjson":
+		return NewJSONLRowReader(r), nil
+	default:
+		return nil, fmt.Errorf("format ต้องเป็น csv หรือ jsonl (ได้ %q)", format)
+	}
+}
+
+// NewRowWriter เลือก writer ตามรูปแบบไฟล์ ("csv" หรือ "jsonl")
+// includeHash กำหนดว่าจะเขียน password_hash ออกไปด้วยหรือไม่ (ใช้ตอนย้ายระบบเท่านั้น)
+func NewRowWriter(format string, w io.Writer, includeHash bool) (RowWriter, error) {
+	switch format {
+	case "csv":
+		return NewCSVRowWriter(w, includeHash)
+	case "jsonl", "ndjson":
+		return NewJSONLRowWriter(w, includeHash), nil
+	default:
+		return nil, fmt.Errorf("format ต้องเป็น csv หรือ jsonl (ได้ %q)", format)
+	}
+}
+
+// CSVRowReader อ่านไฟล์ CSV ที่บรรทัดแรกเป็นชื่อคอลัมน์
+// คอลัมน์ที่รู้จัก: email, name, password, password_hash, attributes (JSON object) และ attr.<key>
+type CSVRowReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+// NewCSVRowReader อ่าน header และตรวจชื่อคอลัมน์ก่อนเริ่มนำเข้า
+func NewCSVRowReader(r io.Reader) (*CSVRowReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", i18n.NewError(i18n.MsgImportHeaderUnreadable), err)
+	}
+
+	columns := make([]string, len(header))
+	hasEmail := false
+	for i, col := range header {
+		// Excel ชอบใส่ BOM ไว้หน้าไฟล์
+		col = strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))
+		switch {
+		case col == "email":
+			hasEmail = true
+		case col == "name", col == "password", col == "password_hash", col == "attributes":
+		case strings.HasPrefix(col, attrColumnPrefix) && len(col) > len(attrColumnPrefix):
+		default:
+			return nil, i18n.NewError(i18n.MsgImportUnknownColumn, col)
+		}
+		columns[i] = col
+	}
+	if !hasEmail {
+		return nil, i18n.NewError(i18n.MsgImportEmailColumn)
+	}
+
+	return &CSVRowReader{r: cr, columns: columns}, nil
+}
+
+func (c *CSVRowReader) Next() (ImportRow, error) {
+	record, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return ImportRow{}, io.EOF
+	}
+	line, _ := c.r.FieldPos(0)
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return ImportRow{}, newRowError(parseErr.StartLine, "", i18n.MsgImportCSVSyntax, parseErr.Err)
+		}
+		return ImportRow{}, err
+	}
+	if len(record) != len(c.columns) {
+		return ImportRow{}, newRowError(line, "", i18n.MsgImportColumnCount, len(record), len(c.columns))
+	}
+
+	row := ImportRow{Line: line}
+	for i, value := range record {
+		switch col := c.columns[i]; col {
+		case "email":
+			row.Email = value
+		case "name":
+			row.Name = value
+		case "password":
+			row.Password = value
+		case "password_hash":
+			row.PasswordHash = value
+		case "attributes":
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			var attrs map[string]any
+			if err := json.Unmarshal([]byte(value), &attrs); err != nil {
+				return ImportRow{}, newRowError(line, row.Email, i18n.MsgImportAttributes)
+			}
+			if row.Attributes == nil {
+				row.Attributes = map[string]any{}
+			}
+			for k, v := range attrs {
+				row.Attributes[k] = v
+			}
+		default:
+			if value == "" {
+				continue
+			}
+			if row.Attributes == nil {
+				row.Attributes = map[string]any{}
+			}
+			row.Attributes[strings.TrimPrefix(col, attrColumnPrefix)] = value
+		}
+	}
+	return row, nil
+}
+
+// jsonlRow คือรูปแบบของหนึ่งบรรทัดในไฟล์ JSON Lines ทั้งขาเข้าและขาออก
+type jsonlRow struct {
+	ID           int            `json:"id,omitempty"`
+	Email        string         `json:"email"`
+	Name         string         `json:"name"`
+	Password     string         `json:"password,omitempty"`
+	PasswordHash string         `json:"password_hash,omitempty"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	AvatarURL    string         `json:"avatar_url,omitempty"`
+	CreatedAt    *time.Time     `json:"created_at,omitempty"`
+}
+
+// JSONLRowReader อ่านไฟล์ที่แต่ละบรรทัดเป็น JSON object หนึ่งผู้ใช้ (ข้ามบรรทัดว่าง)
+type JSONLRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewJSONLRowReader คืน reader ที่อ่านจาก r ทีละบรรทัด
+func NewJSONLRowReader(r io.Reader) *JSONLRowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxJSONLLine)
+	return &JSONLRowReader{scanner: scanner}
+}
+
+func (j *JSONLRowReader) Next() (ImportRow, error) {
+	for j.scanner.Scan() {
+		j.line++
+		text := bytes.TrimSpace(j.scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var in jsonlRow
+		if err := json.Unmarshal(text, &in); err != nil {
+			return ImportRow{}, newRowError(j.line, "", i18n.MsgImportInvalidJSON)
+		}
+		return ImportRow{
+			Line:         j.line,
+			Email:        in.Email,
+			Name:         in.Name,
+			Password:     in.Password,
+			PasswordHash: in.PasswordHash,
+			Attributes:   in.Attributes,
+		}, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return ImportRow{}, fmt.Errorf("บรรทัด %d ยาวเกิน %d ไบต์", j.line+1, maxJSONLLine)
+		}
+		return ImportRow{}, err
+	}
+	return ImportRow{}, io.EOF
+}
+
+// CSVRowWriter เขียนผู้ใช้เป็น CSV พร้อม header
+type CSVRowWriter struct {
+	w           *csv.Writer
+	includeHash bool
+}
+
+// NewCSVRowWriter เขียน header ทันทีแล้วคืน writer
+func NewCSVRowWriter(w io.Writer, includeHash bool) (*CSVRowWriter, error) {
+	cw := csv.NewWriter(w)
+	header := []string{"id", "email", "name", "attributes", "avatar_url", "created_at"}
+	if includeHash {
+		header = append(header, "password_hash")
+	}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &CSVRowWriter{w: cw, includeHash: includeHash}, nil
+}
+
+func (c *CSVRowWriter) Write(u User) error {
+	attrs := "{}"
+	if len(u.Attributes) > 0 {
+		b, err := json.Marshal(u.Attributes)
+		if err != nil {
+			return fmt.Errorf("encode attributes: %w", err)
+		}
+		attrs = string(b)
+	}
+	record := []string{
+		strconv.Itoa(u.ID),
+		u.Email,
+		u.Name,
+		attrs,
+		u.AvatarURL,
+		u.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if c.includeHash {
+		record = append(record, u.PasswordHash)
+	}
+	return c.w.Write(record)
+}
+
+func (c *CSVRowWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// JSONLRowWriter เขียนผู้ใช้เป็น JSON Lines
+type JSONLRowWriter struct {
+	enc         *json.Encoder
+	includeHash bool
+}
+
+// NewJSONLRowWriter คืน writer ที่เขียนหนึ่ง JSON object ต่อบรรทัด
+func NewJSONLRowWriter(w io.Writer, includeHash bool) *JSONLRowWriter {
+	return &JSONLRowWriter{enc: json.NewEncoder(w), includeHash: includeHash}
+}
+
+func (j *JSONLRowWriter) Write(u User) error {
+	created := u.CreatedAt.UTC()
+	out := jsonlRow{
+		ID:         u.ID,
+		Email:      u.Email,
+		Name:       u.Name,
+		Attributes: u.Attributes,
+		AvatarURL:  u.AvatarURL,
+		CreatedAt:  &created,
+	}
+	if j.includeHash {
+		out.PasswordHash = u.PasswordHash
+	}
+	return j.enc.Encode(out)
+}
+
+func (j *JSONLRowWriter) Flush() error {
+	return nil
+}
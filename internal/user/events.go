@@ -0,0 +1,15 @@
+package user
+
+// RegisteredEvent คือ data ของ event user.registered
+// Source บอกว่าบัญชีมาจากการสมัครเอง (register) หรือการนำเข้า (import)
+type RegisteredEvent struct {
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// ProfileUpdatedEvent คือ data ของ event user.profile_updated (ค่าหลังแก้ไข)
+type ProfileUpdatedEvent struct {
+	Name       string         `json:"name"`
+	Attributes map[string]any `json:"attributes"`
+}
@@ -0,0 +1,286 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryRepository เก็บผู้ใช้ไว้ในหน่วยความจำ ใช้รันเซิร์ฟเวอร์โดยไม่มีฐานข้อมูลหรือใช้ในเทสต์
+// พฤติกรรมตรงกับ implementation บน Postgres: อีเมลห้ามซ้ำ (ErrDuplicateEmail),
+// หาไม่เจอคืน error ที่ห่อ pgx.ErrNoRows และลำดับผลลัพธ์ของ List/Stream เหมือนกัน
+// ข้อมูลไม่ถูกเก็บถาวรและไม่ rollback ตาม transaction
+type MemoryRepository struct {
+	mu      sync.RWMutex
+	users   map[int]User
+	byEmail map[string]int
+	nextID  int
+	now     func() time.Time
+	members func(ctx context.Context, orgID int) ([]int, error)
+}
+
+// NewMemoryRepository คืน repository ว่างที่ปลอดภัยต่อการใช้หลาย goroutine
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		users:   map[int]User{},
+		byEmail: map[string]int{},
+		nextID:  1,
+		now:     time.Now,
+	}
+}
+
+// SetMemberLookup กำหนดฟังก์ชันที่คืน id ของสมาชิกในองค์กร ใช้กับ ListFilter.OrgID
+// (ฝั่ง Postgres ใช้ตาราง memberships) ถ้าไม่กำหนด List ที่กรององค์กรจะได้ผลว่าง
+func (m *MemoryRepository) SetMemberLookup(fn func(ctx context.Context, orgID int) ([]int, error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.members = fn
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, u User) (User, error) {
+	attrs, err := cloneAttributes(u.Attributes)
+	if err != nil {
+		return User{}, fmt.Errorf("insert user: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.byEmail[u.Email]; ok {
+		return User{}, fmt.Errorf("insert user: %w", ErrDuplicateEmail)
+	}
+
+	u.ID = m.nextID
+	m.nextID++
+	u.Attributes = attrs
+	u.AvatarURL = ""
+	// Postgres เก็บเวลาละเอียดระดับไมโครวินาที
+	u.CreatedAt = m.now().Truncate(time.Microsecond)
+	m.users[u.ID] = u
+	m.byEmail[u.Email] = u.ID
+	return m.copyOf(u), nil
+}
+
+func (m *MemoryRepository) FindByEmail(ctx context.Context, email string) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	id, ok := m.byEmail[email]
+	if !ok {
+		return User{}, fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	return m.copyOf(m.users[id]), nil
+}
+
+func (m *MemoryRepository) FindByID(ctx context.Context, id int) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	u, ok := m.users[id]
+	if !ok {
+		return User{}, fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	return m.copyOf(u), nil
+}
+
+func (m *MemoryRepository) UpdatePassword(ctx context.Context, userID int, newHash string) error {
+	return m.update(userID, func(u *User) error {
+		u.PasswordHash = newHash
+		return nil
+	})
+}
+
+func (m *MemoryRepository) ReplacePassword(ctx context.Context, userID int, oldHash, newHash string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[userID]
+	if !ok || u.PasswordHash != oldHash {
+		return false, nil
+	}
+	u.PasswordHash = newHash
+	m.users[userID] = u
+	return true, nil
+}
+
+func (m *MemoryRepository) RewriteHashPrefix(ctx context.Context, from, to string, afterID, limit int) (int, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]int, 0, len(m.users))
+	for id := range m.users {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) == 0 || limit <= 0 {
+		return 0, 0, nil
+	}
+	ids = ids[:min(len(ids), limit)]
+
+	changed := 0
+	for _, id := range ids {
+		u := m.users[id]
+		if rest, ok := strings.CutPrefix(u.PasswordHash, from); ok {
+			u.PasswordHash = to + rest
+			m.users[id] = u
+			changed++
+		}
+	}
+	return ids[len(ids)-1], changed, nil
+}
+
+func (m *MemoryRepository) UpdateProfile(ctx context.Context, u User) error {
+	attrs, err := cloneAttributes(u.Attributes)
+	if err != nil {
+		return fmt.Errorf("update profile: %w", err)
+	}
+	return m.update(u.ID, func(stored *User) error {
+		stored.Name = u.Name
+		stored.Attributes = attrs
+		return nil
+	})
+}
+
+func (m *MemoryRepository) UpdateAvatar(ctx context.Context, userID int, url string) error {
+	return m.update(userID, func(u *User) error {
+		u.AvatarURL = url
+		return nil
+	})
+}
+
+func (m *MemoryRepository) update(id int, fn func(u *User) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	if err := fn(&u); err != nil {
+		return err
+	}
+	m.users[id] = u
+	return nil
+}
+
+func (m *MemoryRepository) List(ctx context.Context, filter ListFilter) ([]User, error) {
+	var allowed map[int]bool
+	if filter.OrgID > 0 {
+		m.mu.RLock()
+		lookup := m.members
+		m.mu.RUnlock()
+
+		allowed = map[int]bool{}
+		if lookup != nil {
+			ids, err := lookup(ctx, filter.OrgID)
+			if err != nil {
+				return nil, fmt.Errorf("query users: %w", err)
+			}
+			for _, id := range ids {
+				allowed[id] = true
+			}
+		}
+	}
+
+	m.mu.RLock()
+	var users []User
+	for _, u := range m.users {
+		if allowed != nil && !allowed[u.ID] {
+			continue
+		}
+		if !matchAttributes(u.Attributes, filter.Attributes) {
+			continue
+		}
+		users = append(users, m.copyOf(u))
+	}
+	m.mu.RUnlock()
+
+	// ตรงกับ ORDER BY created_at DESC ถ้าเวลาเท่ากันให้คนที่สร้างทีหลังขึ้นก่อน
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.After(users[j].CreatedAt)
+		}
+		return users[i].ID > users[j].ID
+	})
+	return users, nil
+}
+
+// Stream ส่งผู้ใช้ให้ fn เรียงตาม id จาก snapshot ณ ตอนเรียก fn จึงเรียก repository ซ้ำได้โดยไม่ติด lock
+func (m *MemoryRepository) Stream(ctx context.Context, fn func(User) error) error {
+	m.mu.RLock()
+	users := make([]User, 0, len(m.users))
+	for _, u := range m.users {
+		users = append(users, m.copyOf(u))
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	for _, u := range users {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("iterate users: %w", err)
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	delete(m.users, id)
+	delete(m.byEmail, u.Email)
+	return nil
+}
+
+// copyOf คืนสำเนาที่ผู้เรียกแก้ไขได้โดยไม่กระทบข้อมูลที่เก็บไว้ (attributes ถูก clone แล้วตอนเขียน)
+func (m *MemoryRepository) copyOf(u User) User {
+	u.Attributes, _ = cloneAttributes(u.Attributes)
+	return u
+}
+
+// cloneAttributes ทำสำเนาแบบเดียวกับการเก็บลง JSONB แล้วอ่านกลับ (ตัวเลขกลายเป็น float64)
+func cloneAttributes(attrs map[string]any) (map[string]any, error) {
+	if attrs == nil {
+		return map[string]any{}, nil
+	}
+	b, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, fmt.Errorf("encode attributes: %w", err)
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("decode attributes: %w", err)
+	}
+	return out, nil
+}
+
+// matchAttributes เลียนแบบ attributes ->> key = value ของ Postgres
+// ค่าที่ไม่ใช่ string ถูกเทียบในรูปข้อความ JSON เช่น true หรือ 42 และค่า null ไม่ตรงกับอะไรเลย
+func matchAttributes(attrs map[string]any, want map[string]string) bool {
+	for k, v := range want {
+		got, ok := attrs[k]
+		if !ok || got == nil {
+			return false
+		}
+		text, isString := got.(string)
+		if !isString {
+			b, err := json.Marshal(got)
+			if err != nil {
+				return false
+			}
+			text = string(b)
+		}
+		if text != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,14 @@
+package user_test
+
+import (
+	"testing"
+
+	"fristGoproject/internal/user"
+	"fristGoproject/internal/user/usertest"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	usertest.RunRepositoryTests(t, func(t *testing.T) user.Repository {
+		return user.NewMemoryRepository()
+	})
+}
@@ -4,9 +4,19 @@ import "time"
 
 // User แทนแถวเดียวในตาราง users
 type User struct {
-	ID           int       `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	Name         string    `json:"name"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           int            `json:"id"`
+	Email        string         `json:"email"`
+	PasswordHash string         `json:"-"`
+	Name         string         `json:"name"`
+	Attributes   map[string]any `json:"attributes"`
+	AvatarURL    string         `json:"avatar_url,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// ListFilter คือเงื่อนไขสำหรับค้นรายชื่อผู้ใช้
+// OrgID > 0 จำกัดผลลัพธ์เฉพาะสมาชิกขององค์กรนั้น (tenant ของผู้เรียก)
+// Attributes จับคู่แบบเท่ากันกับค่าใน custom attributes (เทียบเป็นข้อความ)
+type ListFilter struct {
+	OrgID      int
+	Attributes map[string]string
 }
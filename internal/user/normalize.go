@@ -0,0 +1,38 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fristGoproject/internal/db"
+	"fristGoproject/pkg/password"
+)
+
+// normalizeBatch คือจำนวนผู้ใช้ที่ NormalizePasswordHashes ไล่ต่อหนึ่งคำสั่ง
+const normalizeBatch = 500
+
+// NormalizePasswordHashes แปลง hash Argon2id รูปเดิมของระบบ (ไม่มี $ นำหน้า) เป็นรูป PHC มาตรฐานทีละ batch จนครบทุกคน
+// ไม่ต้องรู้รหัสผ่านและไม่ทับรหัสที่ถูกเปลี่ยนระหว่างทาง พัก pause ระหว่าง batch เพื่อไม่แย่งฐานกับงานปกติ
+// รันซ้ำหรือรันพร้อมกันหลาย instance ได้ คืนจำนวนแถวที่แปลง
+func (s *Service) NormalizePasswordHashes(ctx context.Context, pause time.Duration) (int, error) {
+	ctx = db.WithPrimary(ctx)
+	total, afterID := 0, 0
+	for {
+		lastID, changed, err := s.repo.RewriteHashPrefix(ctx, password.LegacyArgon2Prefix, password.Argon2Prefix, afterID, normalizeBatch)
+		if err != nil {
+			return total, fmt.Errorf("แปลงรูปแบบ hash: %w", err)
+		}
+		total += changed
+		if lastID == 0 {
+			return total, nil
+		}
+		afterID = lastID
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(pause):
+		}
+	}
+}
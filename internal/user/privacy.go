@@ -0,0 +1,69 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fristGoproject/internal/event"
+)
+
+// DataSource คือแหล่งข้อมูลอื่นที่ผูกกับผู้ใช้ (เช่น session, audit, identity)
+// แต่ละ package ที่เก็บข้อมูลส่วนบุคคลควร implement แล้วส่งเข้า NewService
+// เพื่อให้การ export และการลบข้อมูล (erasure) ครอบคลุมทุกตาราง
+type DataSource interface {
+	// Name ใช้เป็นชื่อ section ในไฟล์ export เช่น "sessions"
+	Name() string
+	// Export คืนข้อมูลทั้งหมดของผู้ใช้ในรูปที่ encode เป็น JSON ได้
+	Export(ctx context.Context, userID int) (any, error)
+	// Erase ลบหรือทำให้ข้อมูลที่อ้างถึงผู้ใช้ไม่สามารถระบุตัวตนได้ (anonymize)
+	Erase(ctx context.Context, userID int) error
+}
+
+// Export คือชุดข้อมูลทั้งหมดที่ระบบเก็บเกี่ยวกับผู้ใช้หนึ่งคน
+type Export struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	Profile    User           `json:"profile"`
+	Data       map[string]any `json:"data"`
+}
+
+// Export รวบรวมข้อมูลโปรไฟล์และข้อมูลจากทุก DataSource ของผู้ใช้
+func (s *Service) Export(ctx context.Context, userID int) (Export, error) {
+	u, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return Export{}, fmt.Errorf("ค้นหาผู้ใช้: %w", err)
+	}
+	u.PasswordHash = ""
+
+	out := Export{
+		ExportedAt: time.Now().UTC(),
+		Profile:    u,
+		Data:       make(map[string]any, len(s.sources)),
+	}
+	for _, src := range s.sources {
+		data, err := src.Export(ctx, userID)
+		if err != nil {
+			return Export{}, fmt.Errorf("export %s: %w", src.Name(), err)
+		}
+		out.Data[src.Name()] = data
+	}
+	return out, nil
+}
+
+// Erase ลบผู้ใช้ออกจากระบบ โดยให้ทุก DataSource anonymize ข้อมูลที่อ้างถึงก่อน
+// แล้วจึงลบแถวใน users เป็นขั้นตอนสุดท้าย และบันทึก event user.deleted ทั้งหมดอยู่ใน transaction เดียว
+// (ข้อมูลนอกฐาน เช่นไฟล์รูป อาจถูกลบไปแล้วแม้ transaction จะ rollback)
+func (s *Service) Erase(ctx context.Context, userID int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, src := range s.sources {
+			if err := src.Erase(ctx, userID); err != nil {
+				return fmt.Errorf("erase %s: %w", src.Name(), err)
+			}
+		}
+
+		if err := s.repo.Delete(ctx, userID); err != nil {
+			return fmt.Errorf("ลบผู้ใช้: %w", err)
+		}
+		return s.events.Publish(ctx, event.New(event.UserDeleted, userID, nil))
+	})
+}
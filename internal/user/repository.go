@@ -4,53 +4,115 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/i18n"
 )
 
+// ErrDuplicateEmail จะถูกส่งกลับจาก Create เมื่ออีเมลซ้ำกับผู้ใช้ที่มีอยู่ (ตรวจด้วย unique constraint ของฐาน)
+var ErrDuplicateEmail = i18n.NewError(i18n.MsgEmailInUse)
+
 // Repository กำหนดพฤติกรรมที่ layer อื่น (เช่น service) เรียกใช้ข้อมูลผู้ใช้
+// Create คืนผู้ใช้ที่บันทึกแล้วพร้อม id และ created_at ที่ฐานข้อมูลกำหนด
+// ReplacePassword เปลี่ยน password_hash เฉพาะเมื่อค่าเดิมยังเป็น oldHash (ตรวจในคำสั่ง UPDATE เดียว)
+// คืน false ถ้าไม่มีแถวไหนถูกเปลี่ยน เช่นผู้ใช้เปลี่ยนรหัสผ่านไปก่อนแล้ว
+// RewriteHashPrefix ไล่ผู้ใช้ตาม id ที่มากกว่า afterID ไม่เกิน limit คน เปลี่ยนคำนำหน้า password_hash จาก from เป็น to
+// ในคำสั่งเดียว (ไม่ทับรหัสที่เพิ่งเปลี่ยน) คืน id สุดท้ายที่ไล่ถึง (0 = หมดแล้ว) และจำนวนแถวที่เปลี่ยน
 type Repository interface {
-	Create(ctx context.Context, u User) error
+	Create(ctx context.Context, u User) (User, error)
 	FindByEmail(ctx context.Context, email string) (User, error)
+	FindByID(ctx context.Context, id int) (User, error)
 	UpdatePassword(ctx context.Context, userID int, newHash string) error
-	List(ctx context.Context) ([]User, error)
+	ReplacePassword(ctx context.Context, userID int, oldHash, newHash string) (bool, error)
+	RewriteHashPrefix(ctx context.Context, from, to string, afterID, limit int) (lastID, changed int, err error)
+	UpdateProfile(ctx context.Context, u User) error
+	UpdateAvatar(ctx context.Context, userID int, url string) error
+	List(ctx context.Context, filter ListFilter) ([]User, error)
+	Stream(ctx context.Context, fn func(User) error) error
+	Delete(ctx context.Context, id int) error
 }
 
 // repo เป็น implementation ที่ใช้ pgxpool
+// งานเขียนไปที่ primary เสมอ ส่วน List, Stream และการค้นหาทั่วไปอ่านจาก replica
+// ยกเว้น ctx ถูกตรึงด้วย db.WithPrimary (เช่น login หรืออ่านหลังเขียน)
+// ถ้า ctx มี transaction จาก db.TxManager ทุก query จะรันใน transaction นั้น
 type repo struct {
-	pool *pgxpool.Pool
+	cluster *db.Cluster
 }
 
 // NewRepository คืนค่า repository ที่พร้อมใช้งานกับฐานข้อมูล
-func NewRepository(pool *pgxpool.Pool) Repository {
-	return &repo{pool: pool}
+func NewRepository(cluster *db.Cluster) Repository {
+	return &repo{cluster: cluster}
+}
+
+// conn คืนตัวรัน query สำหรับงานเขียน
+func (r *repo) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.cluster.Primary)
 }
 
-func (r *repo) Create(ctx context.Context, u User) error {
+// reader คืนตัวรัน query สำหรับงานอ่านอย่างเดียว
+func (r *repo) reader(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.cluster.Reader(ctx))
+}
+
+func (r *repo) Create(ctx context.Context, u User) (User, error) {
 	const query = `
-		INSERT INTO users (email, password_hash, name)
-		VALUES ($1, $2, $3)
+		INSERT INTO users (email, password_hash, name, attributes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, email, password_hash, name, attributes, avatar_url, created_at
 	`
 
-	_, err := r.pool.Exec(ctx, query, u.Email, u.PasswordHash, u.Name)
-	if err != nil {
-		return fmt.Errorf("insert user: %w", err)
+	attrs := u.Attributes
+	if attrs == nil {
+		attrs = map[string]any{}
 	}
-	return nil
+	row := r.conn(ctx).QueryRow(ctx, query, u.Email, u.PasswordHash, u.Name, attrs)
+
+	var created User
+	if err := row.Scan(&created.ID, &created.Email, &created.PasswordHash, &created.Name, &created.Attributes, &created.AvatarURL, &created.CreatedAt); err != nil {
+		err = db.Translate(err)
+		if errors.Is(err, db.ErrUniqueViolation) {
+			return User{}, fmt.Errorf("insert user: %w", ErrDuplicateEmail)
+		}
+		return User{}, fmt.Errorf("insert user: %w", err)
+	}
+	return created, nil
 }
 
 func (r *repo) FindByEmail(ctx context.Context, email string) (User, error) {
 	const query = `
-		SELECT id, email, password_hash, name, created_at
+		SELECT id, email, password_hash, name, attributes, avatar_url, created_at
 		FROM users
 		WHERE email = $1
 	`
 
-	row := r.pool.QueryRow(ctx, query, email)
+	row := r.reader(ctx).QueryRow(ctx, query, email)
+
+	var u User
+	if err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.Attributes, &u.AvatarURL, &u.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, fmt.Errorf("user not found: %w", err)
+		}
+		return User{}, fmt.Errorf("scan user: %w", err)
+	}
+	return u, nil
+}
+
+func (r *repo) FindByID(ctx context.Context, id int) (User, error) {
+	const query = `
+		SELECT id, email, password_hash, name, attributes, avatar_url, created_at
+		FROM users
+		WHERE id = $1
+	`
+
+	row := r.reader(ctx).QueryRow(ctx, query, id)
 
 	var u User
-	if err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.CreatedAt); err != nil {
+	if err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.Attributes, &u.AvatarURL, &u.CreatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return User{}, fmt.Errorf("user not found: %w", err)
 		}
@@ -66,20 +128,124 @@ func (r *repo) UpdatePassword(ctx context.Context, userID int, newHash string) e
 		WHERE id = $2
 	`
 
-	if _, err := r.pool.Exec(ctx, query, newHash, userID); err != nil {
-		return fmt.Errorf("update password: %w", err)
+	tag, err := r.conn(ctx).Exec(ctx, query, newHash, userID)
+	if err != nil {
+		return fmt.Errorf("update password: %w", db.Translate(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *repo) ReplacePassword(ctx context.Context, userID int, oldHash, newHash string) (bool, error) {
+	const query = `
+		UPDATE users
+		SET password_hash = $3
+		WHERE id = $1 AND password_hash = $2
+	`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, userID, oldHash, newHash)
+	if err != nil {
+		return false, fmt.Errorf("replace password: %w", db.Translate(err))
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *repo) RewriteHashPrefix(ctx context.Context, from, to string, afterID, limit int) (int, int, error) {
+	const window = `
+		SELECT max(id) FROM (
+			SELECT id FROM users WHERE id > $1 ORDER BY id LIMIT $2
+		) AS batch
+	`
+	const query = `
+		UPDATE users
+		SET password_hash = $3::text || substr(password_hash, length($4::text) + 1)
+		WHERE id > $1 AND id <= $2 AND substr(password_hash, 1, length($4::text)) = $4::text
+	`
+
+	var lastID *int
+	if err := r.conn(ctx).QueryRow(ctx, window, afterID, limit).Scan(&lastID); err != nil {
+		return 0, 0, fmt.Errorf("rewrite hash prefix: %w", err)
+	}
+	if lastID == nil {
+		return 0, 0, nil
+	}
+	tag, err := r.conn(ctx).Exec(ctx, query, afterID, *lastID, to, from)
+	if err != nil {
+		return 0, 0, fmt.Errorf("rewrite hash prefix: %w", db.Translate(err))
+	}
+	return *lastID, int(tag.RowsAffected()), nil
+}
+
+func (r *repo) UpdateProfile(ctx context.Context, u User) error {
+	const query = `
+		UPDATE users
+		SET name = $1, attributes = $2
+		WHERE id = $3
+	`
+
+	attrs := u.Attributes
+	if attrs == nil {
+		attrs = map[string]any{}
+	}
+	tag, err := r.conn(ctx).Exec(ctx, query, u.Name, attrs, u.ID)
+	if err != nil {
+		return fmt.Errorf("update profile: %w", db.Translate(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found: %w", pgx.ErrNoRows)
 	}
 	return nil
 }
 
-func (r *repo) List(ctx context.Context) ([]User, error) {
+func (r *repo) UpdateAvatar(ctx context.Context, userID int, url string) error {
 	const query = `
-		SELECT id, email, password_hash, name, created_at
+		UPDATE users
+		SET avatar_url = $1
+		WHERE id = $2
+	`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, url, userID)
+	if err != nil {
+		return fmt.Errorf("update avatar: %w", db.Translate(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *repo) List(ctx context.Context, filter ListFilter) ([]User, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if filter.OrgID > 0 {
+		args = append(args, filter.OrgID)
+		where = append(where, fmt.Sprintf("id IN (SELECT user_id FROM memberships WHERE org_id = $%d)", len(args)))
+	}
+	// เรียง key ให้ query คงที่ (ช่วยเรื่อง prepared statement cache)
+	keys := make([]string, 0, len(filter.Attributes))
+	for k := range filter.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k, filter.Attributes[k])
+		where = append(where, fmt.Sprintf("attributes ->> $%d = $%d", len(args)-1, len(args)))
+	}
+
+	query := `
+		SELECT id, email, password_hash, name, attributes, avatar_url, created_at
 		FROM users
-		ORDER BY created_at DESC
 	`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query users: %w", err)
 	}
@@ -88,7 +254,7 @@ func (r *repo) List(ctx context.Context) ([]User, error) {
 	var users []User
 	for rows.Next() {
 		var u User
-		if err := rows.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.CreatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.Attributes, &u.AvatarURL, &u.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
 		}
 		users = append(users, u)
@@ -100,3 +266,50 @@ func (r *repo) List(ctx context.Context) ([]User, error) {
 
 	return users, nil
 }
+
+func (r *repo) Delete(ctx context.Context, id int) error {
+	const query = `
+		DELETE FROM users
+		WHERE id = $1
+	`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete user: %w", db.Translate(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// Stream อ่านผู้ใช้ทีละแถวแล้วส่งให้ fn โดยไม่โหลดทั้งตารางเข้าหน่วยความจำ
+// ถ้า fn คืน error จะหยุดอ่านและส่ง error นั้นกลับไป
+func (r *repo) Stream(ctx context.Context, fn func(User) error) error {
+	const query = `
+		SELECT id, email, password_hash, name, attributes, avatar_url, created_at
+		FROM users
+		ORDER BY id
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.Attributes, &u.AvatarURL, &u.CreatedAt); err != nil {
+			return fmt.Errorf("scan user: %w", err)
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+
+	if rows.Err() != nil {
+		return fmt.Errorf("iterate users: %w", rows.Err())
+	}
+	return nil
+}
@@ -2,22 +2,53 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/event"
+	"fristGoproject/internal/i18n"
+)
+
+var (
+	// ErrInvalidProfile จะถูกส่งกลับเมื่อข้อมูลโปรไฟล์ไม่ผ่านการตรวจ (error ที่ห่อไว้บอกรายละเอียด)
+	ErrInvalidProfile = i18n.NewError(i18n.MsgInvalidProfile)
+	// ErrNotFound จะถูกส่งกลับเมื่อไม่พบผู้ใช้ (หรือผู้ใช้อยู่นอก tenant ที่ขอ)
+	ErrNotFound = i18n.NewError(i18n.MsgUserNotFound)
 )
 
+// AttributeValidator ตรวจ custom attributes ก่อนบันทึก เช่น *jsonschema.Schema ที่ admin กำหนด
+type AttributeValidator interface {
+	Validate(value any) error
+}
+
 // Service เก็บ logic เพิ่มเติมเกี่ยวกับข้อมูลผู้ใช้ (นอกเหนือจาก auth)
 type Service struct {
-	repo Repository
+	repo      Repository
+	tx        db.Transactor
+	events    event.Publisher
+	sources   []DataSource
+	validator AttributeValidator
 }
 
-// NewService คืน service ที่ใช้ repository เดิม
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+// NewService คืน service ที่ใช้ repository เดิม tx ใช้รันงานหลายขั้นตอนให้เป็น transaction เดียว
+// events รับ domain event ที่เขียนใน transaction เดียวกับการเปลี่ยนข้อมูล
+// sources คือแหล่งข้อมูลอื่นที่ผูกกับผู้ใช้ ใช้ตอน export/erase ข้อมูลส่วนบุคคล
+func NewService(repo Repository, tx db.Transactor, events event.Publisher, sources ...DataSource) *Service {
+	return &Service{repo: repo, tx: tx, events: events, sources: sources}
 }
 
-// List ดึงผู้ใช้ทั้งหมดจากฐานข้อมูล
-func (s *Service) List(ctx context.Context) ([]User, error) {
-	users, err := s.repo.List(ctx)
+// SetAttributeValidator กำหนดตัวตรวจ custom attributes ถ้าไม่กำหนดจะรับ object ใด ๆ ก็ได้
+func (s *Service) SetAttributeValidator(v AttributeValidator) {
+	s.validator = v
+}
+
+// List ดึงผู้ใช้จากฐานข้อมูลตามเงื่อนไขใน filter
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]User, error) {
+	users, err := s.repo.List(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("ดึงรายชื่อผู้ใช้: %w", err)
 	}
@@ -27,3 +58,82 @@ func (s *Service) List(ctx context.Context) ([]User, error) {
 	// }
 	return users, nil
 }
+
+// Get คืนผู้ใช้ตาม id โดยไม่มีรหัสผ่านติดไป ถ้าไม่พบจะได้ ErrNotFound
+func (s *Service) Get(ctx context.Context, id int) (User, error) {
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrNotFound
+		}
+		return User{}, fmt.Errorf("ค้นหาผู้ใช้: %w", err)
+	}
+	u.PasswordHash = ""
+	return u, nil
+}
+
+// ProfileUpdate คือข้อมูลที่ผู้ใช้ขอแก้ไข field ที่เป็น nil จะไม่ถูกแตะ
+// Attributes ใช้หลัก JSON Merge Patch: key ที่ส่งค่า null มาจะถูกลบออก
+type ProfileUpdate struct {
+	Name       *string
+	Attributes map[string]any
+}
+
+// UpdateProfile แก้ชื่อและ custom attributes ของผู้ใช้ แล้วตรวจกับ schema ก่อนบันทึก
+func (s *Service) UpdateProfile(ctx context.Context, userID int, upd ProfileUpdate) (User, error) {
+	var u User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		u, err = s.repo.FindByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("ค้นหาผู้ใช้: %w", err)
+		}
+
+		if upd.Name != nil {
+			name := strings.TrimSpace(*upd.Name)
+			if name == "" {
+				return fmt.Errorf("%w: %w", ErrInvalidProfile, i18n.NewError(i18n.MsgFieldRequired, "name"))
+			}
+			u.Name = name
+		}
+
+		if upd.Attributes != nil {
+			merged := make(map[string]any, len(u.Attributes)+len(upd.Attributes))
+			for k, v := range u.Attributes {
+				merged[k] = v
+			}
+			for k, v := range upd.Attributes {
+				if v == nil {
+					delete(merged, k)
+					continue
+				}
+				merged[k] = v
+			}
+			u.Attributes = merged
+		}
+
+		if s.validator != nil {
+			attrs := u.Attributes
+			if attrs == nil {
+				attrs = map[string]any{}
+			}
+			if err := s.validator.Validate(attrs); err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidProfile, err)
+			}
+		}
+
+		if err := s.repo.UpdateProfile(ctx, u); err != nil {
+			return fmt.Errorf("บันทึกโปรไฟล์: %w", err)
+		}
+		return s.events.Publish(ctx, profileUpdated(u))
+	})
+	if err != nil {
+		return User{}, err
+	}
+	u.PasswordHash = ""
+	return u, nil
+}
+
+func profileUpdated(u User) event.Event {
+	return event.New(event.UserProfileUpdated, u.ID, ProfileUpdatedEvent{Name: u.Name, Attributes: u.Attributes})
+}
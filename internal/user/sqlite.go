@@ -0,0 +1,269 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/db"
+	"fristGoproject/internal/db/sqlite"
+)
+
+// sqliteRepo เป็น implementation บน SQLite สำหรับการติดตั้งที่ไม่มี Postgres
+// หาไม่เจอคืน error ที่ห่อ pgx.ErrNoRows เหมือนฝั่ง Postgres เพื่อให้ service ใช้ร่วมกันได้
+type sqliteRepo struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteRepository คืน repository ที่ใช้ฐาน SQLite จาก sqlite.Open
+func NewSQLiteRepository(sqldb *sql.DB) Repository {
+	return &sqliteRepo{db: sqldb, now: time.Now}
+}
+
+func (r *sqliteRepo) conn(ctx context.Context) sqlite.DBTX {
+	return sqlite.Conn(ctx, r.db)
+}
+
+const sqliteUserColumns = `id, email, password_hash, name, attributes, avatar_url, created_at`
+
+func (r *sqliteRepo) Create(ctx context.Context, u User) (User, error) {
+	const query = `
+		INSERT INTO users (email, password_hash, name, attributes, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING ` + sqliteUserColumns
+
+	attrs, err := encodeAttributes(u.Attributes)
+	if err != nil {
+		return User{}, fmt.Errorf("insert user: %w", err)
+	}
+	row := r.conn(ctx).QueryRowContext(ctx, query, u.Email, u.PasswordHash, u.Name, attrs, sqlite.FormatTime(r.now()))
+	created, err := scanSQLiteUser(row)
+	if err != nil {
+		err = sqlite.Translate(err)
+		if errors.Is(err, db.ErrUniqueViolation) {
+			return User{}, fmt.Errorf("insert user: %w", ErrDuplicateEmail)
+		}
+		return User{}, fmt.Errorf("insert user: %w", err)
+	}
+	return created, nil
+}
+
+func (r *sqliteRepo) FindByEmail(ctx context.Context, email string) (User, error) {
+	query := `SELECT ` + sqliteUserColumns + ` FROM users WHERE email = ?`
+	return r.findOne(ctx, query, email)
+}
+
+func (r *sqliteRepo) FindByID(ctx context.Context, id int) (User, error) {
+	query := `SELECT ` + sqliteUserColumns + ` FROM users WHERE id = ?`
+	return r.findOne(ctx, query, id)
+}
+
+func (r *sqliteRepo) findOne(ctx context.Context, query string, arg any) (User, error) {
+	u, err := scanSQLiteUser(r.conn(ctx).QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return User{}, fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+		}
+		return User{}, fmt.Errorf("scan user: %w", err)
+	}
+	return u, nil
+}
+
+func (r *sqliteRepo) UpdatePassword(ctx context.Context, userID int, newHash string) error {
+	const query = `UPDATE users SET password_hash = ? WHERE id = ?`
+	return r.execOne(ctx, "update password", query, newHash, userID)
+}
+
+func (r *sqliteRepo) ReplacePassword(ctx context.Context, userID int, oldHash, newHash string) (bool, error) {
+	const query = `UPDATE users SET password_hash = ? WHERE id = ? AND password_hash = ?`
+	res, err := r.conn(ctx).ExecContext(ctx, query, newHash, userID, oldHash)
+	if err != nil {
+		return false, fmt.Errorf("replace password: %w", sqlite.Translate(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("replace password: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (r *sqliteRepo) RewriteHashPrefix(ctx context.Context, from, to string, afterID, limit int) (int, int, error) {
+	const window = `SELECT max(id) FROM (SELECT id FROM users WHERE id > ? ORDER BY id LIMIT ?)`
+	const query = `
+		UPDATE users SET password_hash = ? || substr(password_hash, length(?) + 1)
+		WHERE id > ? AND id <= ? AND substr(password_hash, 1, length(?)) = ?`
+
+	var lastID sql.NullInt64
+	if err := r.conn(ctx).QueryRowContext(ctx, window, afterID, limit).Scan(&lastID); err != nil {
+		return 0, 0, fmt.Errorf("rewrite hash prefix: %w", err)
+	}
+	if !lastID.Valid {
+		return 0, 0, nil
+	}
+	res, err := r.conn(ctx).ExecContext(ctx, query, to, from, afterID, lastID.Int64, from, from)
+	if err != nil {
+		return 0, 0, fmt.Errorf("rewrite hash prefix: %w", sqlite.Translate(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, fmt.Errorf("rewrite hash prefix: %w", err)
+	}
+	return int(lastID.Int64), int(n), nil
+}
+
+func (r *sqliteRepo) UpdateProfile(ctx context.Context, u User) error {
+	const query = `UPDATE users SET name = ?, attributes = ? WHERE id = ?`
+	attrs, err := encodeAttributes(u.Attributes)
+	if err != nil {
+		return fmt.Errorf("update profile: %w", err)
+	}
+	return r.execOne(ctx, "update profile", query, u.Name, attrs, u.ID)
+}
+
+func (r *sqliteRepo) UpdateAvatar(ctx context.Context, userID int, url string) error {
+	const query = `UPDATE users SET avatar_url = ? WHERE id = ?`
+	return r.execOne(ctx, "update avatar", query, url, userID)
+}
+
+func (r *sqliteRepo) Delete(ctx context.Context, id int) error {
+	const query = `DELETE FROM users WHERE id = ?`
+	return r.execOne(ctx, "delete user", query, id)
+}
+
+// execOne รันคำสั่งที่ต้องกระทบหนึ่งแถว ถ้าไม่มีแถวไหนถูกแก้คืน not found
+func (r *sqliteRepo) execOne(ctx context.Context, op, query string, args ...any) error {
+	res, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, sqlite.Translate(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *sqliteRepo) List(ctx context.Context, filter ListFilter) ([]User, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if filter.OrgID > 0 {
+		where = append(where, "id IN (SELECT user_id FROM memberships WHERE org_id = ?)")
+		args = append(args, filter.OrgID)
+	}
+	keys := make([]string, 0, len(filter.Attributes))
+	for k := range filter.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		// ให้ผลเหมือน attributes ->> key ของ Postgres ที่คืน true/false เป็นข้อความ
+		// (->> ของ SQLite คืน 1/0 และคืนตัวเลขเป็นชนิดตัวเลข)
+		where = append(where, `CASE json_type(attributes -> ?)
+			WHEN 'true' THEN 'true'
+			WHEN 'false' THEN 'false'
+			ELSE CAST(attributes ->> ? AS TEXT)
+		END = ?`)
+		args = append(args, k, k, filter.Attributes[k])
+	}
+
+	query := `SELECT ` + sqliteUserColumns + ` FROM users`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		u, err := scanSQLiteUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate users: %w", err)
+	}
+	return users, nil
+}
+
+// Stream อ่านผู้ใช้ทีละแถวเรียงตาม id แล้วส่งให้ fn
+func (r *sqliteRepo) Stream(ctx context.Context, fn func(User) error) error {
+	query := `SELECT ` + sqliteUserColumns + ` FROM users ORDER BY id`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u, err := scanSQLiteUser(rows)
+		if err != nil {
+			return fmt.Errorf("scan user: %w", err)
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate users: %w", err)
+	}
+	return nil
+}
+
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSQLiteUser(row sqliteScanner) (User, error) {
+	var (
+		u         User
+		attrs     string
+		createdAt string
+	)
+	if err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &attrs, &u.AvatarURL, &createdAt); err != nil {
+		return User{}, err
+	}
+	if err := json.Unmarshal([]byte(attrs), &u.Attributes); err != nil {
+		return User{}, fmt.Errorf("decode attributes: %w", err)
+	}
+	if u.Attributes == nil {
+		u.Attributes = map[string]any{}
+	}
+	t, err := sqlite.ParseTime(createdAt)
+	if err != nil {
+		return User{}, err
+	}
+	u.CreatedAt = t
+	return u, nil
+}
+
+func encodeAttributes(attrs map[string]any) (string, error) {
+	if attrs == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(attrs)
+	if err != nil {
+		return "", fmt.Errorf("encode attributes: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,22 @@
+package user_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"fristGoproject/internal/db/sqlite"
+	"fristGoproject/internal/user"
+	"fristGoproject/internal/user/usertest"
+)
+
+func TestSQLiteRepository(t *testing.T) {
+	usertest.RunRepositoryTests(t, func(t *testing.T) user.Repository {
+		sqldb, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "users.db"))
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { sqldb.Close() })
+		return user.NewSQLiteRepository(sqldb)
+	})
+}
@@ -0,0 +1,415 @@
+// Package usertest มีชุดทดสอบกลางที่ทุก implementation ของ user.Repository ต้องผ่าน
+// เพื่อให้ in-memory, Postgres, SQLite และ driver อื่นทำงานเหมือนกันจากมุมของ service
+//
+// ใช้จากไฟล์ _test.go ของ implementation นั้น ๆ เช่น
+//
+//	func TestMemoryRepository(t *testing.T) {
+//		usertest.RunRepositoryTests(t, func(t *testing.T) user.Repository {
+//			return user.NewMemoryRepository()
+//		})
+//	}
+//
+// newRepo ต้องคืน repository ที่ว่างเปล่าทุกครั้งที่ถูกเรียก (เช่น truncate ตารางก่อนคืน
+// หรือเปิด SQLite ไฟล์ใหม่ใน t.TempDir())
+// ตัวกรอง ListFilter.OrgID ไม่อยู่ในชุดนี้ เพราะขึ้นกับข้อมูลสมาชิกภาพขององค์กร
+package usertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/user"
+)
+
+// RunRepositoryTests รันทุกกรณีทดสอบกับ repository ที่ newRepo สร้าง
+func RunRepositoryTests(t *testing.T, newRepo func(t *testing.T) user.Repository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo user.Repository)
+	}{
+		{"CreateReturnsStoredUser", testCreateReturnsStoredUser},
+		{"CreateDuplicateEmail", testCreateDuplicateEmail},
+		{"FindByEmailAndID", testFindByEmailAndID},
+		{"NotFound", testNotFound},
+		{"UpdatePassword", testUpdatePassword},
+		{"ReplacePassword", testReplacePassword},
+		{"RewriteHashPrefix", testRewriteHashPrefix},
+		{"UpdateProfile", testUpdateProfile},
+		{"UpdateAvatar", testUpdateAvatar},
+		{"ReturnedUserIsACopy", testReturnedUserIsACopy},
+		{"ListOrderAndFilter", testListOrderAndFilter},
+		{"StreamOrderAndStop", testStreamOrderAndStop},
+		{"Delete", testDelete},
+		{"ConcurrentCreateSameEmail", testConcurrentCreateSameEmail},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+func mustCreate(t *testing.T, repo user.Repository, email string, attrs map[string]any) user.User {
+	t.Helper()
+	u, err := repo.Create(context.Background(), user.User{
+		Email:        email,
+		PasswordHash: "hash-" + email,
+		Name:         "name " + email,
+		Attributes:   attrs,
+	})
+	if err != nil {
+		t.Fatalf("Create(%s): %v", email, err)
+	}
+	return u
+}
+
+func testCreateReturnsStoredUser(t *testing.T, repo user.Repository) {
+	u := mustCreate(t, repo, "a@example.com", nil)
+	if u.ID <= 0 {
+		t.Errorf("ID = %d, want > 0", u.ID)
+	}
+	if u.CreatedAt.IsZero() {
+		t.Error("CreatedAt is zero")
+	}
+	if u.Attributes == nil || len(u.Attributes) != 0 {
+		t.Errorf("Attributes = %#v, want empty non-nil map", u.Attributes)
+	}
+	if u.PasswordHash != "hash-a@example.com" || u.Name != "name a@example.com" || u.AvatarURL != "" {
+		t.Errorf("unexpected user %+v", u)
+	}
+
+	second := mustCreate(t, repo, "b@example.com", nil)
+	if second.ID <= u.ID {
+		t.Errorf("second ID = %d, want > %d", second.ID, u.ID)
+	}
+}
+
+func testCreateDuplicateEmail(t *testing.T, repo user.Repository) {
+	mustCreate(t, repo, "dup@example.com", nil)
+	_, err := repo.Create(context.Background(), user.User{Email: "dup@example.com", PasswordHash: "x", Name: "x"})
+	if !errors.Is(err, user.ErrDuplicateEmail) {
+		t.Fatalf("second Create error = %v, want ErrDuplicateEmail", err)
+	}
+}
+
+func testFindByEmailAndID(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	created := mustCreate(t, repo, "find@example.com", map[string]any{"team": "core", "level": 3})
+
+	byEmail, err := repo.FindByEmail(ctx, "find@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	byID, err := repo.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	for _, got := range []user.User{byEmail, byID} {
+		if got.ID != created.ID || got.Email != created.Email || got.PasswordHash != created.PasswordHash {
+			t.Errorf("got %+v, want %+v", got, created)
+		}
+		if !got.CreatedAt.Equal(created.CreatedAt) {
+			t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, created.CreatedAt)
+		}
+		// ค่าจาก JSON ต้องกลับมาเป็นชนิดเดียวกับที่ encoding/json ให้
+		if got.Attributes["team"] != "core" || got.Attributes["level"] != float64(3) {
+			t.Errorf("Attributes = %#v", got.Attributes)
+		}
+	}
+}
+
+func testNotFound(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	const missing = 1 << 30
+	checks := map[string]error{
+		"FindByID":       func() error { _, err := repo.FindByID(ctx, missing); return err }(),
+		"FindByEmail":    func() error { _, err := repo.FindByEmail(ctx, "nobody@example.com"); return err }(),
+		"UpdatePassword": repo.UpdatePassword(ctx, missing, "x"),
+		"UpdateProfile":  repo.UpdateProfile(ctx, user.User{ID: missing, Name: "x"}),
+		"UpdateAvatar":   repo.UpdateAvatar(ctx, missing, "x"),
+		"Delete":         repo.Delete(ctx, missing),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			t.Errorf("%s error = %v, want pgx.ErrNoRows", name, err)
+		}
+	}
+}
+
+func testUpdatePassword(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	u := mustCreate(t, repo, "pw@example.com", nil)
+	if err := repo.UpdatePassword(ctx, u.ID, "new-hash"); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+	got, err := repo.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.PasswordHash != "new-hash" {
+		t.Errorf("PasswordHash = %q, want new-hash", got.PasswordHash)
+	}
+}
+
+func testReplacePassword(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	u := mustCreate(t, repo, "replace@example.com", nil)
+
+	ok, err := repo.ReplacePassword(ctx, u.ID, "stale-hash", "lost-hash")
+	if err != nil || ok {
+		t.Fatalf("ReplacePassword with stale hash = (%v, %v), want (false, nil)", ok, err)
+	}
+	ok, err = repo.ReplacePassword(ctx, u.ID, u.PasswordHash, "new-hash")
+	if err != nil || !ok {
+		t.Fatalf("ReplacePassword = (%v, %v), want (true, nil)", ok, err)
+	}
+	if ok, err = repo.ReplacePassword(ctx, u.ID+1000, "new-hash", "x"); err != nil || ok {
+		t.Errorf("ReplacePassword unknown user = (%v, %v), want (false, nil)", ok, err)
+	}
+
+	got, err := repo.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.PasswordHash != "new-hash" {
+		t.Errorf("PasswordHash = %q, want new-hash", got.PasswordHash)
+	}
+}
+
+func testRewriteHashPrefix(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	var ids []int
+	for i, hash := range []string{"old$a", "new$b", "old$c", "other$d"} {
+		u := mustCreate(t, repo, fmt.Sprintf("rewrite%d@example.com", i), nil)
+		if err := repo.UpdatePassword(ctx, u.ID, hash); err != nil {
+			t.Fatalf("UpdatePassword: %v", err)
+		}
+		ids = append(ids, u.ID)
+	}
+
+	lastID, changed, err := repo.RewriteHashPrefix(ctx, "old$", "$new$", 0, 2)
+	if err != nil {
+		t.Fatalf("RewriteHashPrefix: %v", err)
+	}
+	if lastID != ids[1] || changed != 1 {
+		t.Errorf("first batch = (%d, %d), want (%d, 1)", lastID, changed, ids[1])
+	}
+	lastID, changed, err = repo.RewriteHashPrefix(ctx, "old$", "$new$", lastID, 2)
+	if err != nil {
+		t.Fatalf("RewriteHashPrefix: %v", err)
+	}
+	if lastID != ids[3] || changed != 1 {
+		t.Errorf("second batch = (%d, %d), want (%d, 1)", lastID, changed, ids[3])
+	}
+	if lastID, _, err = repo.RewriteHashPrefix(ctx, "old$", "$new$", lastID, 2); err != nil || lastID != 0 {
+		t.Errorf("last batch = (%d, %v), want (0, nil)", lastID, err)
+	}
+
+	want := []string{"$new$a", "new$b", "$new$c", "other$d"}
+	for i, id := range ids {
+		got, err := repo.FindByID(ctx, id)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if got.PasswordHash != want[i] {
+			t.Errorf("user %d PasswordHash = %q, want %q", i, got.PasswordHash, want[i])
+		}
+	}
+}
+
+func testUpdateProfile(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	u := mustCreate(t, repo, "profile@example.com", map[string]any{"old": true})
+
+	u.Name = "Renamed"
+	u.Attributes = map[string]any{"phone": "0812345678"}
+	u.Email = "ignored@example.com"
+	u.PasswordHash = "ignored"
+	if err := repo.UpdateProfile(ctx, u); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Name != "Renamed" || got.Email != "profile@example.com" || got.PasswordHash != "hash-profile@example.com" {
+		t.Errorf("UpdateProfile changed more than name/attributes: %+v", got)
+	}
+	if len(got.Attributes) != 1 || got.Attributes["phone"] != "0812345678" {
+		t.Errorf("Attributes = %#v, want replaced map", got.Attributes)
+	}
+
+	u.Attributes = nil
+	if err := repo.UpdateProfile(ctx, u); err != nil {
+		t.Fatalf("UpdateProfile(nil attributes): %v", err)
+	}
+	got, _ = repo.FindByID(ctx, u.ID)
+	if got.Attributes == nil || len(got.Attributes) != 0 {
+		t.Errorf("Attributes after nil update = %#v, want empty map", got.Attributes)
+	}
+}
+
+func testUpdateAvatar(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	u := mustCreate(t, repo, "avatar@example.com", nil)
+	if err := repo.UpdateAvatar(ctx, u.ID, "/media/a.jpg"); err != nil {
+		t.Fatalf("UpdateAvatar: %v", err)
+	}
+	got, _ := repo.FindByID(ctx, u.ID)
+	if got.AvatarURL != "/media/a.jpg" {
+		t.Errorf("AvatarURL = %q", got.AvatarURL)
+	}
+	if err := repo.UpdateAvatar(ctx, u.ID, ""); err != nil {
+		t.Fatalf("UpdateAvatar(clear): %v", err)
+	}
+	got, _ = repo.FindByID(ctx, u.ID)
+	if got.AvatarURL != "" {
+		t.Errorf("AvatarURL after clear = %q", got.AvatarURL)
+	}
+}
+
+func testReturnedUserIsACopy(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	attrs := map[string]any{"team": "core"}
+	u := mustCreate(t, repo, "copy@example.com", attrs)
+	attrs["team"] = "changed by caller"
+	u.Attributes["team"] = "changed by caller"
+
+	got, _ := repo.FindByID(ctx, u.ID)
+	got.Attributes["team"] = "changed again"
+
+	again, _ := repo.FindByID(ctx, u.ID)
+	if again.Attributes["team"] != "core" {
+		t.Errorf("stored attributes were mutated through a returned value: %#v", again.Attributes)
+	}
+}
+
+func testListOrderAndFilter(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	first := mustCreate(t, repo, "l1@example.com", map[string]any{"team": "core", "remote": true})
+	second := mustCreate(t, repo, "l2@example.com", map[string]any{"team": "web"})
+	third := mustCreate(t, repo, "l3@example.com", map[string]any{"team": "core", "remote": false})
+
+	all, err := repo.List(ctx, user.ListFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got, want := ids(all), []int{third.ID, second.ID, first.ID}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("List order = %v, want newest first %v", got, want)
+	}
+
+	core, err := repo.List(ctx, user.ListFilter{Attributes: map[string]string{"team": "core"}})
+	if err != nil {
+		t.Fatalf("List(team=core): %v", err)
+	}
+	if got, want := ids(core), []int{third.ID, first.ID}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("List(team=core) = %v, want %v", got, want)
+	}
+
+	// ค่าที่ไม่ใช่ string เทียบในรูปข้อความ JSON
+	remote, err := repo.List(ctx, user.ListFilter{Attributes: map[string]string{"team": "core", "remote": "true"}})
+	if err != nil {
+		t.Fatalf("List(remote=true): %v", err)
+	}
+	if got, want := ids(remote), []int{first.ID}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("List(team=core, remote=true) = %v, want %v", got, want)
+	}
+
+	none, err := repo.List(ctx, user.ListFilter{Attributes: map[string]string{"missing": "x"}})
+	if err != nil {
+		t.Fatalf("List(missing): %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("List(missing=x) = %v, want empty", ids(none))
+	}
+}
+
+func testStreamOrderAndStop(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	var want []int
+	for i := range 3 {
+		want = append(want, mustCreate(t, repo, fmt.Sprintf("s%d@example.com", i), nil).ID)
+	}
+
+	var got []int
+	err := repo.Stream(ctx, func(u user.User) error {
+		got = append(got, u.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Stream order = %v, want %v", got, want)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.Stream(ctx, func(user.User) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Stream with failing fn: err = %v, calls = %d; want stop after 1 call", err, calls)
+	}
+}
+
+func testDelete(t *testing.T, repo user.Repository) {
+	ctx := context.Background()
+	u := mustCreate(t, repo, "delete@example.com", nil)
+	if err := repo.Delete(ctx, u.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, u.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("FindByID after Delete error = %v, want pgx.ErrNoRows", err)
+	}
+	// อีเมลของผู้ใช้ที่ถูกลบต้องใช้สมัครใหม่ได้
+	if _, err := repo.Create(ctx, user.User{Email: "delete@example.com", PasswordHash: "x", Name: "x"}); err != nil {
+		t.Errorf("Create after Delete: %v", err)
+	}
+}
+
+func testConcurrentCreateSameEmail(t *testing.T, repo user.Repository) {
+	const workers = 8
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		dup       int
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Create(context.Background(), user.User{Email: "race@example.com", PasswordHash: "x", Name: "x"})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, user.ErrDuplicateEmail):
+				dup++
+			default:
+				t.Errorf("Create: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if succeeded != 1 || dup != workers-1 {
+		t.Errorf("succeeded = %d, duplicates = %d; want 1 and %d", succeeded, dup, workers-1)
+	}
+}
+
+func ids(users []user.User) []int {
+	out := make([]int, len(users))
+	for i, u := range users {
+		out[i] = u.ID
+	}
+	return out
+}
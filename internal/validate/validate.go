@@ -0,0 +1,232 @@
+// Package validate ตรวจ struct ของคำขอตามกฎใน tag `validate` เช่น
+//
+//	Email string `json:"email" validate:"required,email"`
+//	Name  string `json:"name" validate:"required,name,max=100"`
+//
+// ตรวจทุก field แล้วคืนทุกจุดที่ไม่ผ่านพร้อมกัน ชื่อ field ใช้ชื่อใน tag json ให้ตรงกับที่ client ส่ง
+// ข้อความเป็น i18n.Message เพื่อให้ชั้น HTTP แปลตามภาษาของคำขอ
+//
+// กฎที่มี:
+//
+//	required   string ต้องไม่ว่าง, ตัวเลขต้องไม่เป็นศูนย์, slice/map ต้องมีสมาชิก
+//	email      รูปแบบ addr-spec ตาม RFC 5322 (ไม่มีชื่อแสดง) รองรับโดเมน IDN เช่น ตัวอย่าง.ไทย
+//	name       ชื่อคน: ตัวอักษรทุกภาษา เครื่องหมายกำกับ ช่องว่าง และ ' - .
+//	printable  ห้ามมีอักขระควบคุมหรืออักขระที่มองไม่เห็น
+//	sha256hex  SHA-256 hex 64 ตัวอักษร (มี 0x นำหน้าได้)
+//	url        URL แบบเต็มที่เป็น http หรือ https
+//	oneof=a b  ต้องเป็นค่าใดค่าหนึ่งในรายการ (คั่นด้วยช่องว่าง)
+//	min=N      string ยาวอย่างน้อย N ตัวอักษร หรือตัวเลขมีค่าอย่างน้อย N
+//	max=N      string ยาวไม่เกิน N ตัวอักษร หรือตัวเลขมีค่าไม่เกิน N
+//
+// ค่าว่างผ่านทุกกฎยกเว้น required (field ที่ไม่บังคับจึงไม่ต้องส่งก็ได้)
+// field แบบ pointer ที่เป็น nil ถือว่าไม่ได้ส่งมาและข้ามทุกกฎ ถ้าส่งมาจะตรวจเหมือน field ปกติ
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+
+	"fristGoproject/internal/i18n"
+)
+
+// Violation คือ field หนึ่งที่ไม่ผ่านกฎ Args ไม่รวมชื่อ field (ข้อความกลุ่ม i18n.MsgField* รับชื่อ field เป็นอาร์กิวเมนต์แรก)
+type Violation struct {
+	Field   string
+	Message i18n.Message
+	Args    []any
+}
+
+// Violations รวมทุกจุดที่ไม่ผ่านจากการตรวจหนึ่งครั้ง
+type Violations []Violation
+
+func (v Violations) Error() string {
+	msgs := make([]string, len(v))
+	for i, violation := range v {
+		msgs[i] = i18n.T(i18n.Default, violation.Message, append([]any{violation.Field}, violation.Args...)...)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Struct ตัดช่องว่างหัวท้ายของ string ทุก field ที่มี tag validate แล้วตรวจตามกฎ
+// ptr ต้องเป็น pointer ไปยัง struct (เพื่อให้แก้ค่าที่ตัดช่องว่างแล้วได้) คืน nil ถ้าผ่านทุกกฎ
+func Struct(ptr any) Violations {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: ต้องส่ง pointer ไปยัง struct (ได้ %T)", ptr))
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	var violations Violations
+	for i := range rt.NumField() {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("validate")
+		if !ok || !sf.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.String {
+			fv.SetString(strings.TrimSpace(fv.String()))
+		}
+
+		field := FieldName(sf)
+		for _, rule := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(rule, "=")
+			if v, failed := check(name, param, fv); failed {
+				v.Field = field
+				violations = append(violations, v)
+				// ค่าว่างไม่ต้องรายงานกฎอื่นซ้ำ
+				if name == "required" {
+					break
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// FieldName คืนชื่อ field ตาม tag json (หรือชื่อใน Go ถ้าไม่มี tag)
+func FieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+// check คืน Violation (ยังไม่มีชื่อ field) และ true ถ้าค่าไม่ผ่านกฎ name
+func check(name, param string, v reflect.Value) (Violation, bool) {
+	if name != "required" && v.IsZero() {
+		return Violation{}, false
+	}
+
+	switch name {
+	case "required":
+		if v.IsZero() || (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+			return Violation{Message: i18n.MsgFieldRequired}, true
+		}
+	case "email":
+		if !Email(v.String()) {
+			return Violation{Message: i18n.MsgFieldEmail}, true
+		}
+	case "name":
+		if !personName(v.String()) {
+			return Violation{Message: i18n.MsgFieldName}, true
+		}
+	case "printable":
+		if strings.IndexFunc(v.String(), func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+			return Violation{Message: i18n.MsgFieldPrintable}, true
+		}
+	case "sha256hex":
+		if !sha256Hex(v.String()) {
+			return Violation{Message: i18n.MsgFieldSHA256Hex}, true
+		}
+	case "url":
+		u, err := url.Parse(v.String())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Violation{Message: i18n.MsgFieldURL}, true
+		}
+	case "oneof":
+		options := strings.Fields(param)
+		if !slices.Contains(options, fmt.Sprint(v.Interface())) {
+			return Violation{Message: i18n.MsgFieldOneOf, Args: []any{strings.Join(options, ", ")}}, true
+		}
+	case "min", "max":
+		limit, err := strconv.Atoi(param)
+		if err != nil {
+			panic(fmt.Sprintf("validate: %s ต้องเป็นตัวเลข (ได้ %q)", name, param))
+		}
+		return checkBound(name, limit, v)
+	default:
+		panic(fmt.Sprintf("validate: ไม่รู้จักกฎ %q", name))
+	}
+	return Violation{}, false
+}
+
+func checkBound(name string, limit int, v reflect.Value) (Violation, bool) {
+	var n int64
+	isLength := false
+	switch v.Kind() {
+	case reflect.String:
+		n, isLength = int64(utf8.RuneCountInString(v.String())), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = v.Int()
+	default:
+		panic(fmt.Sprintf("validate: ใช้ %s กับ %s ไม่ได้", name, v.Kind()))
+	}
+
+	switch {
+	case name == "min" && n < int64(limit) && isLength:
+		return Violation{Message: i18n.MsgFieldMinLength, Args: []any{limit}}, true
+	case name == "min" && n < int64(limit):
+		return Violation{Message: i18n.MsgFieldMin, Args: []any{limit}}, true
+	case name == "max" && n > int64(limit) && isLength:
+		return Violation{Message: i18n.MsgFieldMaxLength, Args: []any{limit}}, true
+	case name == "max" && n > int64(limit):
+		return Violation{Message: i18n.MsgFieldMax, Args: []any{limit}}, true
+	}
+	return Violation{}, false
+}
+
+// Email ตรวจว่าเป็น addr-spec ตาม RFC 5322 เช่น user@example.com (ไม่รับชื่อแสดงหรือ <...>)
+// local part เป็น UTF-8 ได้ (RFC 6531) ส่วนโดเมนต้องแปลงเป็น ASCII ด้วย IDNA ได้และมีอย่างน้อยสองระดับ
+func Email(s string) bool {
+	if len(s) > 254 {
+		return false
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return false
+	}
+	at := strings.LastIndexByte(s, '@')
+	local, domain := s[:at], s[at+1:]
+	if len(local) > 64 || strings.HasPrefix(domain, "[") {
+		return false
+	}
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil || !strings.Contains(strings.Trim(ascii, "."), ".") {
+		return false
+	}
+	return true
+}
+
+// personName รับตัวอักษรทุกภาษาพร้อมเครื่องหมายกำกับ (เช่น สระและวรรณยุกต์ไทย) ช่องว่างเดี่ยว และ ' ’ - .
+func personName(s string) bool {
+	if strings.Contains(s, "  ") {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r), unicode.Is(unicode.M, r):
+		case r == ' ', r == '\'', r == '’', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func sha256Hex(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(s) != 64 {
+		return false
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return !('0' <= r && r <= '9' || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F')
+	}) < 0
+}
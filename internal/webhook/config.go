@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config คือค่าของ Worker อ่านจาก env ด้วย LoadConfig
+type Config struct {
+	PollInterval time.Duration
+	// Timeout คือเวลาที่รอ endpoint ตอบต่อการส่งหนึ่งครั้ง
+	Timeout time.Duration
+	// MaxAttempts คือจำนวนครั้งสูงสุดก่อนเปลี่ยนเป็น failed
+	MaxAttempts int
+	// Retention คือเวลาที่เก็บประวัติการส่งที่จบแล้ว (0 = ไม่ลบ)
+	Retention time.Duration
+}
+
+func defaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		Timeout:      10 * time.Second,
+		MaxAttempts:  12,
+		Retention:    30 * 24 * time.Hour,
+	}
+}
+
+// LoadConfig อ่านค่าจาก env
+//
+//	WEBHOOK_POLL_INTERVAL  ความถี่ตรวจรายการที่ถึงเวลาส่ง (default 1s)
+//	WEBHOOK_TIMEOUT        เวลารอ endpoint ตอบ (default 10s)
+//	WEBHOOK_MAX_ATTEMPTS   จำนวนครั้งสูงสุดก่อนเป็น failed (default 12 ≈ ลองใหม่ราว 1 วัน)
+//	WEBHOOK_RETENTION      เก็บประวัติการส่งนานเท่าไร (default 720h, 0 = ไม่ลบ)
+func LoadConfig() (Config, error) {
+	cfg := defaultConfig()
+	var errs []error
+
+	readDuration := func(key string, dst *time.Duration) {
+		if raw := os.Getenv(key); raw != "" {
+			v, err := time.ParseDuration(raw)
+			if err != nil || v < 0 {
+				errs = append(errs, fmt.Errorf("%s ต้องเป็นช่วงเวลา เช่น 30s หรือ 5m (ได้ %q)", key, raw))
+				return
+			}
+			*dst = v
+		}
+	}
+	readDuration("WEBHOOK_POLL_INTERVAL", &cfg.PollInterval)
+	readDuration("WEBHOOK_TIMEOUT", &cfg.Timeout)
+	readDuration("WEBHOOK_RETENTION", &cfg.Retention)
+	if raw := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			errs = append(errs, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS ต้องเป็นจำนวนเต็มบวก (ได้ %q)", raw))
+		} else {
+			cfg.MaxAttempts = v
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryRepository เก็บ endpoint และรายการส่งไว้ในหน่วยความจำ ใช้คู่กับ DB_DRIVER=memory
+type MemoryRepository struct {
+	mu         sync.Mutex
+	endpoints  map[int]Endpoint
+	deliveries map[int64]Delivery
+	nextEP     int
+	nextID     int64
+	now        func() time.Time
+}
+
+// NewMemoryRepository คืน repository ว่าง
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		endpoints:  map[int]Endpoint{},
+		deliveries: map[int64]Delivery{},
+		nextEP:     1,
+		nextID:     1,
+		now:        func() time.Time { return time.Now().UTC().Truncate(time.Microsecond) },
+	}
+}
+
+func (m *MemoryRepository) CreateEndpoint(ctx context.Context, ep Endpoint) (Endpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ep.ID = m.nextEP
+	m.nextEP++
+	ep.EventTypes = slices.Clone(ep.EventTypes)
+	ep.CreatedAt = m.now()
+	m.endpoints[ep.ID] = ep
+	return ep, nil
+}
+
+func (m *MemoryRepository) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	endpoints := make([]Endpoint, 0, len(m.endpoints))
+	for _, ep := range m.endpoints {
+		ep.EventTypes = slices.Clone(ep.EventTypes)
+		endpoints = append(endpoints, ep)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+	return endpoints, nil
+}
+
+// DeleteEndpoint ลบรายการส่งของ endpoint ไปด้วย เหมือน ON DELETE CASCADE
+func (m *MemoryRepository) DeleteEndpoint(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.endpoints[id]; !ok {
+		return fmt.Errorf("webhook endpoint not found: %w", pgx.ErrNoRows)
+	}
+	delete(m.endpoints, id)
+	for did, d := range m.deliveries {
+		if d.EndpointID == id {
+			delete(m.deliveries, did)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) CreateDeliveries(ctx context.Context, deliveries []Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range deliveries {
+		if _, ok := m.endpoints[d.EndpointID]; !ok {
+			return fmt.Errorf("insert webhook delivery: endpoint %d does not exist", d.EndpointID)
+		}
+		if m.hasOriginal(d.EndpointID, d.EventID) {
+			continue
+		}
+		m.insert(d, nil)
+	}
+	return nil
+}
+
+func (m *MemoryRepository) CreateReplay(ctx context.Context, original Delivery) (Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := original.ID
+	return m.insert(original, &id), nil
+}
+
+func (m *MemoryRepository) hasOriginal(endpointID int, eventID int64) bool {
+	for _, d := range m.deliveries {
+		if d.EndpointID == endpointID && d.EventID == eventID && d.ReplayOf == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryRepository) insert(src Delivery, replayOf *int64) Delivery {
+	now := m.now()
+	d := Delivery{
+		ID:            m.nextID,
+		EndpointID:    src.EndpointID,
+		EventID:       src.EventID,
+		EventType:     src.EventType,
+		Payload:       slices.Clone(src.Payload),
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		ReplayOf:      replayOf,
+		CreatedAt:     now,
+	}
+	m.nextID++
+	m.deliveries[d.ID] = d
+	return d
+}
+
+func (m *MemoryRepository) FindDelivery(ctx context.Context, id int64) (Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.deliveries[id]
+	if !ok {
+		return Delivery{}, fmt.Errorf("webhook delivery not found: %w", pgx.ErrNoRows)
+	}
+	return d, nil
+}
+
+func (m *MemoryRepository) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deliveries := []Delivery{}
+	for _, d := range m.deliveries {
+		if filter.EndpointID > 0 && d.EndpointID != filter.EndpointID {
+			continue
+		}
+		if filter.Status != "" && d.Status != filter.Status {
+			continue
+		}
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	if filter.Limit > 0 && len(deliveries) > filter.Limit {
+		deliveries = deliveries[:filter.Limit]
+	}
+	return deliveries, nil
+}
+
+func (m *MemoryRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	var due []Delivery
+	for _, d := range m.deliveries {
+		if d.Status == StatusPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].Attempts++
+		due[i].NextAttemptAt = now.Add(lease)
+		m.deliveries[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (m *MemoryRepository) RecordAttempt(ctx context.Context, id int64, result AttemptResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.deliveries[id]
+	if !ok {
+		return nil
+	}
+	now := m.now()
+	d.Status = result.Status
+	d.ResponseCode = nil
+	if result.ResponseCode != 0 {
+		code := result.ResponseCode
+		d.ResponseCode = &code
+	}
+	d.ResponseBody = result.ResponseBody
+	d.LastError = result.Error
+	d.NextAttemptAt = now.Add(result.RetryIn)
+	d.DeliveredAt = nil
+	if result.Status == StatusSucceeded {
+		d.DeliveredAt = &now
+	}
+	m.deliveries[id] = d
+	return nil
+}
+
+func (m *MemoryRepository) PruneDeliveries(ctx context.Context, age time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := m.now().Add(-age)
+	var n int64
+	for id, d := range m.deliveries {
+		if d.Status != StatusPending && d.CreatedAt.Before(cutoff) {
+			delete(m.deliveries, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryRepository) ListDeliveriesByUser(ctx context.Context, userID int) ([]Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deliveries := []Delivery{}
+	for _, d := range m.deliveries {
+		if payloadUserID(d.Payload) == userID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (m *MemoryRepository) RedactDeliveries(ctx context.Context, userID int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for id, d := range m.deliveries {
+		if payloadUserID(d.Payload) != userID {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(d.Payload, &fields); err != nil {
+			return n, fmt.Errorf("decode webhook payload: %w", err)
+		}
+		fields["data"] = json.RawMessage("{}")
+		payload, err := json.Marshal(fields)
+		if err != nil {
+			return n, fmt.Errorf("encode webhook payload: %w", err)
+		}
+		d.Payload = payload
+		d.ResponseBody = ""
+		m.deliveries[id] = d
+		n++
+	}
+	return n, nil
+}
+
+// payloadUserID อ่าน user_id จาก payload (event.Event ที่ encode แล้ว) คืน 0 ถ้าอ่านไม่ได้
+func payloadUserID(payload json.RawMessage) int {
+	var e struct {
+		UserID int `json:"user_id"`
+	}
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return 0
+	}
+	return e.UserID
+}
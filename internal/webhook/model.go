@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"fristGoproject/internal/event"
+)
+
+// AllEvents ใน EventTypes หมายถึงรับทุก event รวมถึง event ที่เพิ่มในอนาคต
+const AllEvents event.Type = "*"
+
+// Endpoint คือ URL ที่ admin ลงทะเบียนไว้รับ event (แถวใน webhook_endpoints)
+// Secret ใช้ลงลายเซ็น HMAC และแสดงให้ admin เห็นครั้งเดียวตอนสร้าง
+type Endpoint struct {
+	ID          int          `json:"id"`
+	URL         string       `json:"url"`
+	Secret      string       `json:"secret,omitempty"`
+	EventTypes  []event.Type `json:"events"`
+	Description string       `json:"description"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// Subscribed บอกว่า endpoint รับ event ประเภท t หรือไม่
+func (ep Endpoint) Subscribed(t event.Type) bool {
+	return slices.Contains(ep.EventTypes, AllEvents) || slices.Contains(ep.EventTypes, t)
+}
+
+// Status คือสถานะของการส่งหนึ่งรายการ
+type Status string
+
+const (
+	// StatusPending ยังรอส่ง (ครั้งแรกหรือรอลองใหม่)
+	StatusPending Status = "pending"
+	// StatusSucceeded ปลายทางตอบ 2xx แล้ว
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed ลองครบจำนวนครั้งแล้วยังไม่สำเร็จ (replay ได้)
+	StatusFailed Status = "failed"
+)
+
+// Valid บอกว่าเป็นสถานะที่ระบบรู้จักหรือไม่
+func (s Status) Valid() bool {
+	return s == StatusPending || s == StatusSucceeded || s == StatusFailed
+}
+
+// Delivery คือการส่ง event หนึ่งตัวไปยัง endpoint หนึ่งแห่ง (แถวใน webhook_deliveries)
+// ResponseCode และ ResponseBody เก็บผลของครั้งล่าสุด ReplayOf ชี้ไปยังรายการต้นฉบับเมื่อถูกสั่งส่งซ้ำ
+type Delivery struct {
+	ID            int64           `json:"id"`
+	EndpointID    int             `json:"endpoint_id"`
+	EventID       int64           `json:"event_id"`
+	EventType     event.Type      `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        Status          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  *int            `json:"response_code,omitempty"`
+	ResponseBody  string          `json:"response_body,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	ReplayOf      *int64          `json:"replay_of,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// DeliveryFilter คือเงื่อนไขของ ListDeliveries ค่าศูนย์คือไม่กรอง
+type DeliveryFilter struct {
+	EndpointID int
+	Status     Status
+	Limit      int
+}
+
+// AttemptResult คือผลของการส่งหนึ่งครั้งที่ worker บันทึกกลับลง repository
+type AttemptResult struct {
+	Status       Status
+	ResponseCode int // 0 = ไม่ได้รับ response (เช่น ต่อไม่ติด)
+	ResponseBody string
+	Error        string
+	// RetryIn ใช้เมื่อ Status เป็น pending
+	RetryIn time.Duration
+}
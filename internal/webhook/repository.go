@@ -0,0 +1,316 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/db"
+)
+
+// Repository กำหนดพฤติกรรมที่ service และ worker ใช้กับ endpoint และรายการส่ง
+// หาไม่เจอคืน error ที่ห่อ pgx.ErrNoRows ทุก implementation
+type Repository interface {
+	CreateEndpoint(ctx context.Context, ep Endpoint) (Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id int) error
+	// CreateDeliveries สร้างรายการส่งใหม่ รายการที่มี (endpoint, event) ซ้ำกับที่มีอยู่แล้วจะถูกข้าม
+	// เพราะ outbox อาจส่ง event เดิมซ้ำ (ไม่ใช้กับรายการ replay)
+	CreateDeliveries(ctx context.Context, deliveries []Delivery) error
+	// CreateReplay สร้างรายการส่งใหม่ที่คัดลอก payload จาก original
+	CreateReplay(ctx context.Context, original Delivery) (Delivery, error)
+	FindDelivery(ctx context.Context, id int64) (Delivery, error)
+	// ListDeliveries คืนรายการล่าสุดก่อน
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error)
+	// ClaimDeliveries จองรายการ pending ที่ถึงเวลาส่งเป็นเวลา lease และนับ attempts เพิ่มหนึ่ง
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	RecordAttempt(ctx context.Context, id int64, result AttemptResult) error
+	// PruneDeliveries ลบรายการที่จบแล้ว (succeeded/failed) ที่สร้างนานกว่า age
+	PruneDeliveries(ctx context.Context, age time.Duration) (int64, error)
+	// ListDeliveriesByUser คืนรายการส่งของ event ที่เป็นของผู้ใช้ (user_id ใน payload) เรียงตาม id
+	ListDeliveriesByUser(ctx context.Context, userID int) ([]Delivery, error)
+	// RedactDeliveries ล้าง data ใน payload และ response_body ของรายการส่งของผู้ใช้ คืนจำนวนแถวที่แก้
+	RedactDeliveries(ctx context.Context, userID int) (int64, error)
+}
+
+// repo เป็น implementation บน Postgres ใช้เวลาของฐานข้อมูลในการนัดส่ง
+type repo struct {
+	db db.DBTX
+}
+
+// NewRepository คืน repository ที่ใช้ pool ของ primary
+func NewRepository(conn db.DBTX) Repository {
+	return &repo{db: conn}
+}
+
+func (r *repo) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.db)
+}
+
+const deliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts,
+	response_code, response_body, last_error, next_attempt_at, delivered_at, replay_of, created_at`
+
+func (r *repo) CreateEndpoint(ctx context.Context, ep Endpoint) (Endpoint, error) {
+	const query = `
+		INSERT INTO webhook_endpoints (url, secret, event_types, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	types, err := json.Marshal(ep.EventTypes)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("encode event types: %w", err)
+	}
+	if err := r.conn(ctx).QueryRow(ctx, query, ep.URL, ep.Secret, types, ep.Description).Scan(&ep.ID, &ep.CreatedAt); err != nil {
+		return Endpoint{}, fmt.Errorf("insert webhook endpoint: %w", db.Translate(err))
+	}
+	return ep, nil
+}
+
+func (r *repo) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	const query = `
+		SELECT id, url, secret, event_types, description, created_at
+		FROM webhook_endpoints
+		ORDER BY id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	endpoints := []Endpoint{}
+	for rows.Next() {
+		var ep Endpoint
+		if err := rows.Scan(&ep.ID, &ep.URL, &ep.Secret, &ep.EventTypes, &ep.Description, &ep.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, ep)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook endpoints: %w", rows.Err())
+	}
+	return endpoints, nil
+}
+
+func (r *repo) DeleteEndpoint(ctx context.Context, id int) error {
+	const query = `DELETE FROM webhook_endpoints WHERE id = $1`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook endpoint: %w", db.Translate(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("webhook endpoint not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *repo) CreateDeliveries(ctx context.Context, deliveries []Delivery) error {
+	const query = `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (endpoint_id, event_id) WHERE replay_of IS NULL DO NOTHING
+	`
+
+	for _, d := range deliveries {
+		if _, err := r.conn(ctx).Exec(ctx, query, d.EndpointID, d.EventID, d.EventType, []byte(d.Payload)); err != nil {
+			return fmt.Errorf("insert webhook delivery: %w", db.Translate(err))
+		}
+	}
+	return nil
+}
+
+func (r *repo) CreateReplay(ctx context.Context, original Delivery) (Delivery, error) {
+	const query = `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, replay_of)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + deliveryColumns
+
+	row := r.conn(ctx).QueryRow(ctx, query, original.EndpointID, original.EventID, original.EventType, []byte(original.Payload), original.ID)
+	d, err := scanDelivery(row)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("insert webhook replay: %w", db.Translate(err))
+	}
+	return d, nil
+}
+
+func (r *repo) FindDelivery(ctx context.Context, id int64) (Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	d, err := scanDelivery(r.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Delivery{}, fmt.Errorf("webhook delivery not found: %w", err)
+		}
+		return Delivery{}, fmt.Errorf("scan webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+func (r *repo) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if filter.EndpointID > 0 {
+		args = append(args, filter.EndpointID)
+		where = append(where, "endpoint_id = $"+strconv.Itoa(len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where = append(where, "status = $"+strconv.Itoa(len(args)))
+	}
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", rows.Err())
+	}
+	return deliveries, nil
+}
+
+// ClaimDeliveries ใช้ FOR UPDATE SKIP LOCKED ให้หลาย instance ส่งพร้อมกันได้โดยไม่ชนกัน
+func (r *repo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = NOW() + make_interval(secs => $2), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	rows, err := r.conn(ctx).Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", rows.Err())
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (r *repo) RecordAttempt(ctx context.Context, id int64, result AttemptResult) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			response_code = $3,
+			response_body = $4,
+			last_error = $5,
+			next_attempt_at = NOW() + make_interval(secs => $6),
+			delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() END
+		WHERE id = $1
+	`
+
+	var code *int
+	if result.ResponseCode != 0 {
+		code = &result.ResponseCode
+	}
+	_, err := r.conn(ctx).Exec(ctx, query, id, result.Status, code, result.ResponseBody, result.Error, result.RetryIn.Seconds())
+	if err != nil {
+		return fmt.Errorf("record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) PruneDeliveries(ctx context.Context, age time.Duration) (int64, error) {
+	const query = `
+		DELETE FROM webhook_deliveries
+		WHERE status <> 'pending' AND created_at < NOW() - make_interval(secs => $1)
+	`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, age.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("prune webhook deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanDelivery(row pgx.Row) (Delivery, error) {
+	var (
+		d       Delivery
+		payload []byte
+	)
+	err := row.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.ResponseCode, &d.ResponseBody, &d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.ReplayOf, &d.CreatedAt)
+	d.Payload = payload
+	return d, err
+}
+
+func (r *repo) ListDeliveriesByUser(ctx context.Context, userID int) ([]Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE (payload->>'user_id')::int = $1 ORDER BY id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", rows.Err())
+	}
+	return deliveries, nil
+}
+
+func (r *repo) RedactDeliveries(ctx context.Context, userID int) (int64, error) {
+	const query = `
+		UPDATE webhook_deliveries
+		SET payload = jsonb_set(payload, '{data}', '{}'::jsonb), response_body = ''
+		WHERE (payload->>'user_id')::int = $1
+	`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("redact webhook deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,203 @@
+// Package webhook ส่ง domain event ไปยัง URL ที่ admin ลงทะเบียนไว้ พร้อมลายเซ็น HMAC-SHA256
+// event จาก outbox ถูกแตกเป็นรายการส่ง (delivery) หนึ่งรายการต่อ endpoint แล้ว Worker ส่งและลองใหม่แยกกัน
+// endpoint ที่ล่มจึงไม่ถ่วง endpoint อื่นหรือ sink อื่นของ outbox
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/event"
+	"fristGoproject/internal/i18n"
+)
+
+var (
+	// ErrInvalidEndpoint ใช้กับข้อมูล endpoint ที่ไม่ผ่านการตรวจ (error ที่ห่อไว้บอกรายละเอียด)
+	ErrInvalidEndpoint = i18n.NewError(i18n.MsgWebhookInvalid)
+	// ErrEndpointNotFound จะถูกส่งกลับเมื่อไม่พบ endpoint ที่ระบุ
+	ErrEndpointNotFound = i18n.NewError(i18n.MsgWebhookNotFound)
+	// ErrDeliveryNotFound จะถูกส่งกลับเมื่อไม่พบรายการส่งที่ระบุ
+	ErrDeliveryNotFound = i18n.NewError(i18n.MsgDeliveryNotFound)
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// Service จัดการ endpoint รายการส่ง และแปลง event จาก outbox เป็นรายการส่ง
+type Service struct {
+	repo Repository
+}
+
+// NewService คืน service ที่ใช้ repo
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// CreateEndpoint ลงทะเบียน URL ใหม่ที่รับ event ตาม types ("*" = ทุก event) และสร้าง secret ให้
+func (s *Service) CreateEndpoint(ctx context.Context, rawURL string, types []event.Type, description string) (Endpoint, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Endpoint{}, fmt.Errorf("%w: %w", ErrInvalidEndpoint, i18n.NewError(i18n.MsgFieldURL, "url"))
+	}
+	if len(types) == 0 {
+		return Endpoint{}, fmt.Errorf("%w: %w", ErrInvalidEndpoint, i18n.NewError(i18n.MsgWebhookEvents))
+	}
+	var subscribed []event.Type
+	for _, t := range types {
+		if t != AllEvents && !slices.Contains(event.Types, t) {
+			return Endpoint{}, fmt.Errorf("%w: %w", ErrInvalidEndpoint, i18n.NewError(i18n.MsgWebhookUnknownEvent, t))
+		}
+		if !slices.Contains(subscribed, t) {
+			subscribed = append(subscribed, t)
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return Endpoint{}, err
+	}
+	ep, err := s.repo.CreateEndpoint(ctx, Endpoint{
+		URL:         rawURL,
+		Secret:      secret,
+		EventTypes:  subscribed,
+		Description: strings.TrimSpace(description),
+	})
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("บันทึก webhook: %w", err)
+	}
+	return ep, nil
+}
+
+// ListEndpoints คืน endpoint ทั้งหมดโดยไม่เปิดเผย secret
+func (s *Service) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	endpoints, err := s.repo.ListEndpoints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ดึงรายการ webhook: %w", err)
+	}
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	return endpoints, nil
+}
+
+// DeleteEndpoint ลบ endpoint พร้อมประวัติการส่งทั้งหมดของ endpoint นั้น
+func (s *Service) DeleteEndpoint(ctx context.Context, id int) error {
+	if err := s.repo.DeleteEndpoint(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrEndpointNotFound
+		}
+		return fmt.Errorf("ลบ webhook: %w", err)
+	}
+	return nil
+}
+
+// Deliveries คืนประวัติการส่งล่าสุดตาม filter (ไม่ระบุ Limit = 50 สูงสุด 200)
+func (s *Service) Deliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEndpoint, i18n.NewError(i18n.MsgFieldOneOf, "status", "pending, succeeded, failed"))
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	filter.Limit = min(filter.Limit, maxListLimit)
+
+	deliveries, err := s.repo.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("ดึงประวัติการส่ง webhook: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Replay สั่งส่งรายการเดิมอีกครั้ง (payload เดิม ลายเซ็นและ timestamp ใหม่) เป็นรายการส่งใหม่
+// รายการต้นฉบับยังอยู่ในประวัติเหมือนเดิม
+func (s *Service) Replay(ctx context.Context, deliveryID int64) (Delivery, error) {
+	original, err := s.repo.FindDelivery(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Delivery{}, ErrDeliveryNotFound
+		}
+		return Delivery{}, fmt.Errorf("ค้นหารายการส่ง: %w", err)
+	}
+	d, err := s.repo.CreateReplay(ctx, original)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("สร้างรายการส่งซ้ำ: %w", err)
+	}
+	return d, nil
+}
+
+// Name ใช้เป็นชื่อ section ใน user export (implement user.DataSource)
+func (s *Service) Name() string {
+	return "webhook_deliveries"
+}
+
+// Export คืนรายการส่ง webhook ของ event ที่เป็นของผู้ใช้ (implement user.DataSource)
+func (s *Service) Export(ctx context.Context, userID int) (any, error) {
+	deliveries, err := s.repo.ListDeliveriesByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ดึงรายการส่ง webhook: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Erase ล้าง data ใน payload และ response ของรายการส่งของผู้ใช้ (implement user.DataSource)
+// ประวัติการส่งยังอยู่ให้ admin ดูได้ แต่ไม่เหลือ email หรือชื่อ
+func (s *Service) Erase(ctx context.Context, userID int) error {
+	if _, err := s.repo.RedactDeliveries(ctx, userID); err != nil {
+		return fmt.Errorf("ล้างข้อมูลในรายการส่ง webhook: %w", err)
+	}
+	return nil
+}
+
+// Sink คืน event.Sink ที่สร้างรายการส่งให้ทุก endpoint ที่รับ event นั้น ใช้ร่วมกับ sink อื่นใน event.Dispatcher
+func (s *Service) Sink() event.Sink {
+	return fanout{repo: s.repo}
+}
+
+type fanout struct {
+	repo Repository
+}
+
+func (fanout) Name() string { return "webhooks" }
+
+func (f fanout) Send(ctx context.Context, e event.Event) error {
+	endpoints, err := f.repo.ListEndpoints(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []Delivery
+	for _, ep := range endpoints {
+		if ep.Subscribed(e.Type) {
+			deliveries = append(deliveries, Delivery{EndpointID: ep.ID, EventID: e.ID, EventType: e.Type, Payload: payload})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return f.repo.CreateDeliveries(ctx, deliveries)
+}
+
+// newSecret สุ่ม secret 32 byte ในรูปที่ใส่ใน config ได้ง่าย
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
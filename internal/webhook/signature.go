@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader มีค่าในรูป t=<unix>,v1=<hex> โดย v1 = HMAC-SHA256(secret, "<t>.<body>")
+	SignatureHeader = "X-Webhook-Signature"
+	// DefaultTolerance คือช่วงเวลาที่ผู้รับควรยอมรับ timestamp เพื่อกัน replay attack
+	DefaultTolerance = 5 * time.Minute
+)
+
+// ErrInvalidSignature ถูกส่งกลับจาก Verify เมื่อ header ไม่ถูกต้อง ลายเซ็นไม่ตรง หรือเก่าเกิน tolerance
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign คืนค่า header SignatureHeader ของ body ที่ส่งเวลา ts
+func Sign(secret string, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify ตรวจลายเซ็นฝั่งผู้รับ (ใช้ใน Go service อื่นหรือเป็นตัวอย่างสำหรับภาษาอื่น)
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var (
+		t    string
+		sigs [][]byte
+	)
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return ErrInvalidSignature
+	}
+	expected := mac(secret, t, body)
+	for _, sig := range sigs {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func mac(secret, t string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(t))
+	h.Write([]byte{'.'})
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,306 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fristGoproject/internal/db/sqlite"
+)
+
+// sqliteRepo เป็น implementation บน SQLite ใช้ฐานเดียวกับ repository อื่นจาก sqlite.Open
+type sqliteRepo struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteRepository คืน repository ที่ใช้ฐาน SQLite
+func NewSQLiteRepository(sqldb *sql.DB) Repository {
+	return &sqliteRepo{db: sqldb, now: time.Now}
+}
+
+func (r *sqliteRepo) conn(ctx context.Context) sqlite.DBTX {
+	return sqlite.Conn(ctx, r.db)
+}
+
+func (r *sqliteRepo) CreateEndpoint(ctx context.Context, ep Endpoint) (Endpoint, error) {
+	const query = `
+		INSERT INTO webhook_endpoints (url, secret, event_types, description, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	types, err := json.Marshal(ep.EventTypes)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("encode event types: %w", err)
+	}
+	ep.CreatedAt = r.now().UTC().Truncate(time.Microsecond)
+	row := r.conn(ctx).QueryRowContext(ctx, query, ep.URL, ep.Secret, string(types), ep.Description, sqlite.FormatTime(ep.CreatedAt))
+	if err := row.Scan(&ep.ID); err != nil {
+		return Endpoint{}, fmt.Errorf("insert webhook endpoint: %w", sqlite.Translate(err))
+	}
+	return ep, nil
+}
+
+func (r *sqliteRepo) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	const query = `
+		SELECT id, url, secret, event_types, description, created_at
+		FROM webhook_endpoints
+		ORDER BY id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	endpoints := []Endpoint{}
+	for rows.Next() {
+		var (
+			ep        Endpoint
+			types     string
+			createdAt string
+		)
+		if err := rows.Scan(&ep.ID, &ep.URL, &ep.Secret, &types, &ep.Description, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan webhook endpoint: %w", err)
+		}
+		if err := json.Unmarshal([]byte(types), &ep.EventTypes); err != nil {
+			return nil, fmt.Errorf("decode event types: %w", err)
+		}
+		if ep.CreatedAt, err = sqlite.ParseTime(createdAt); err != nil {
+			return nil, fmt.Errorf("scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, ep)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook endpoints: %w", rows.Err())
+	}
+	return endpoints, nil
+}
+
+func (r *sqliteRepo) DeleteEndpoint(ctx context.Context, id int) error {
+	const query = `DELETE FROM webhook_endpoints WHERE id = ?`
+
+	res, err := r.conn(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook endpoint: %w", sqlite.Translate(err))
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete webhook endpoint: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("webhook endpoint not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *sqliteRepo) CreateDeliveries(ctx context.Context, deliveries []Delivery) error {
+	const query = `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (endpoint_id, event_id) WHERE replay_of IS NULL DO NOTHING
+	`
+
+	now := sqlite.FormatTime(r.now())
+	for _, d := range deliveries {
+		if _, err := r.conn(ctx).ExecContext(ctx, query, d.EndpointID, d.EventID, d.EventType, string(d.Payload), now, now); err != nil {
+			return fmt.Errorf("insert webhook delivery: %w", sqlite.Translate(err))
+		}
+	}
+	return nil
+}
+
+func (r *sqliteRepo) CreateReplay(ctx context.Context, original Delivery) (Delivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, replay_of, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + deliveryColumns
+
+	now := sqlite.FormatTime(r.now())
+	row := r.conn(ctx).QueryRowContext(ctx, query, original.EndpointID, original.EventID, original.EventType,
+		string(original.Payload), original.ID, now, now)
+	d, err := scanSQLiteDelivery(row)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("insert webhook replay: %w", sqlite.Translate(err))
+	}
+	return d, nil
+}
+
+func (r *sqliteRepo) FindDelivery(ctx context.Context, id int64) (Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = ?`
+
+	d, err := scanSQLiteDelivery(r.conn(ctx).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Delivery{}, fmt.Errorf("webhook delivery not found: %w", pgx.ErrNoRows)
+		}
+		return Delivery{}, fmt.Errorf("scan webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+func (r *sqliteRepo) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if filter.EndpointID > 0 {
+		where = append(where, "endpoint_id = ?")
+		args = append(args, filter.EndpointID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	return r.queryDeliveries(ctx, query, args...)
+}
+
+func (r *sqliteRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= ?
+			ORDER BY id
+			LIMIT ?
+		)
+		RETURNING ` + deliveryColumns
+
+	now := r.now()
+	deliveries, err := r.queryDeliveries(ctx, query, sqlite.FormatTime(now.Add(lease)), sqlite.FormatTime(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (r *sqliteRepo) queryDeliveries(ctx context.Context, query string, args ...any) ([]Delivery, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query webhook deliveries: %w", sqlite.Translate(err))
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		d, err := scanSQLiteDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", rows.Err())
+	}
+	return deliveries, nil
+}
+
+func (r *sqliteRepo) RecordAttempt(ctx context.Context, id int64, result AttemptResult) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = ?, response_code = ?, response_body = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+		WHERE id = ?
+	`
+
+	now := r.now()
+	var (
+		code        *int
+		deliveredAt *string
+	)
+	if result.ResponseCode != 0 {
+		code = &result.ResponseCode
+	}
+	if result.Status == StatusSucceeded {
+		t := sqlite.FormatTime(now)
+		deliveredAt = &t
+	}
+	_, err := r.conn(ctx).ExecContext(ctx, query, result.Status, code, result.ResponseBody, result.Error,
+		sqlite.FormatTime(now.Add(result.RetryIn)), deliveredAt, id)
+	if err != nil {
+		return fmt.Errorf("record webhook attempt: %w", sqlite.Translate(err))
+	}
+	return nil
+}
+
+func (r *sqliteRepo) PruneDeliveries(ctx context.Context, age time.Duration) (int64, error) {
+	const query = `DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < ?`
+
+	res, err := r.conn(ctx).ExecContext(ctx, query, sqlite.FormatTime(r.now().Add(-age)))
+	if err != nil {
+		return 0, fmt.Errorf("prune webhook deliveries: %w", sqlite.Translate(err))
+	}
+	return res.RowsAffected()
+}
+
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSQLiteDelivery(row sqliteScanner) (Delivery, error) {
+	var (
+		d                      Delivery
+		payload                string
+		code                   sql.NullInt64
+		replayOf               sql.NullInt64
+		nextAttempt, createdAt string
+		deliveredAt            sql.NullString
+	)
+	err := row.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&code, &d.ResponseBody, &d.LastError, &nextAttempt, &deliveredAt, &replayOf, &createdAt)
+	if err != nil {
+		return Delivery{}, err
+	}
+	d.Payload = json.RawMessage(payload)
+	if code.Valid {
+		c := int(code.Int64)
+		d.ResponseCode = &c
+	}
+	if replayOf.Valid {
+		d.ReplayOf = &replayOf.Int64
+	}
+	if d.NextAttemptAt, err = sqlite.ParseTime(nextAttempt); err != nil {
+		return Delivery{}, err
+	}
+	if d.DeliveredAt, err = sqlite.ParseNullTime(deliveredAt); err != nil {
+		return Delivery{}, err
+	}
+	if d.CreatedAt, err = sqlite.ParseTime(createdAt); err != nil {
+		return Delivery{}, err
+	}
+	return d, nil
+}
+
+func (r *sqliteRepo) ListDeliveriesByUser(ctx context.Context, userID int) ([]Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE json_extract(payload, '$.user_id') = ? ORDER BY id`
+	return r.queryDeliveries(ctx, query, userID)
+}
+
+func (r *sqliteRepo) RedactDeliveries(ctx context.Context, userID int) (int64, error) {
+	const query = `
+		UPDATE webhook_deliveries
+		SET payload = json_set(payload, '$.data', json('{}')), response_body = ''
+		WHERE json_extract(payload, '$.user_id') = ?
+	`
+
+	res, err := r.conn(ctx).ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("redact webhook deliveries: %w", sqlite.Translate(err))
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"fristGoproject/internal/db"
+)
+
+const (
+	minRetryDelay = 10 * time.Second
+	maxRetryDelay = 6 * time.Hour
+	// maxResponseBody คือความยาวของ response ที่เก็บไว้ในประวัติการส่ง
+	maxResponseBody = 1 << 10
+	// workerConcurrency คือจำนวนรายการที่ส่งพร้อมกันในหนึ่ง batch
+	workerConcurrency = 8
+	batchSize         = 4 * workerConcurrency
+)
+
+// Worker ส่งรายการที่ถึงเวลาไปยัง endpoint พร้อมลายเซ็น แล้วบันทึกผลและนัดลองใหม่
+// รายการที่ลองครบ MaxAttempts ครั้งจะเป็น failed และไม่ส่งอีกจนกว่า admin จะสั่ง replay
+type Worker struct {
+	repo   Repository
+	client *http.Client
+	cfg    Config
+}
+
+// NewWorker คืน worker ที่ใช้ค่าจาก cfg (ค่าที่เป็นศูนย์ใช้ default ของ LoadConfig)
+// client ไม่ตาม redirect เพราะลายเซ็นผูกกับ URL ที่ลงทะเบียนไว้
+func NewWorker(repo Repository, cfg Config) *Worker {
+	def := defaultConfig()
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = def.PollInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &Worker{repo: repo, client: client, cfg: cfg}
+}
+
+// Run ส่งเป็นรอบ ๆ จนกว่า ctx จะถูกยกเลิก
+func (w *Worker) Run(ctx context.Context) {
+	ctx = db.WithPrimary(ctx)
+	lastPrune := time.Time{}
+	for {
+		n, err := w.DeliverOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("webhook deliver: %v", err)
+		}
+
+		if w.cfg.Retention > 0 && time.Since(lastPrune) > time.Hour {
+			lastPrune = time.Now()
+			if pruned, err := w.repo.PruneDeliveries(ctx, w.cfg.Retention); err != nil {
+				log.Printf("webhook prune: %v", err)
+			} else if pruned > 0 {
+				log.Printf("webhook prune: removed %d deliveries", pruned)
+			}
+		}
+
+		if n == batchSize && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+// DeliverOnce จองและส่งหนึ่ง batch คืนจำนวนรายการที่หยิบมา
+func (w *Worker) DeliverOnce(ctx context.Context) (int, error) {
+	// lease ต้องนานกว่าเวลาส่งทั้ง batch ที่แย่ที่สุด
+	lease := w.cfg.Timeout*time.Duration(batchSize/workerConcurrency+1) + time.Minute
+	deliveries, err := w.repo.ClaimDeliveries(ctx, batchSize, lease)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+	endpoints, err := w.repo.ListEndpoints(ctx)
+	if err != nil {
+		return len(deliveries), err
+	}
+	byID := make(map[int]Endpoint, len(endpoints))
+	for _, ep := range endpoints {
+		byID[ep.ID] = ep
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, workerConcurrency)
+	)
+	for _, d := range deliveries {
+		ep, ok := byID[d.EndpointID]
+		if !ok {
+			// endpoint ถูกลบระหว่างทาง รายการจะหายไปกับ ON DELETE CASCADE
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := w.send(ctx, ep, d)
+			if err := w.repo.RecordAttempt(ctx, d.ID, result); err != nil {
+				log.Printf("webhook delivery %d: %v", d.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (w *Worker) send(ctx context.Context, ep Endpoint, d Delivery) AttemptResult {
+	fail := func(code int, body, cause string) AttemptResult {
+		result := AttemptResult{ResponseCode: code, ResponseBody: body, Error: cause}
+		if d.Attempts >= w.cfg.MaxAttempts {
+			result.Status = StatusFailed
+			log.Printf("webhook delivery %d to endpoint %d failed after %d attempts: %s", d.ID, ep.ID, d.Attempts, cause)
+			return result
+		}
+		result.Status = StatusPending
+		result.RetryIn = retryDelay(d.Attempts)
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return fail(0, "", err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ingoapi-webhooks")
+	req.Header.Set("X-Webhook-ID", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-Event-ID", strconv.FormatInt(d.EventID, 10))
+	req.Header.Set("X-Event-Type", string(d.EventType))
+	req.Header.Set(SignatureHeader, Sign(ep.Secret, time.Now(), d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fail(0, "", err.Error())
+	}
+	defer resp.Body.Close()
+	body := readBody(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fail(resp.StatusCode, body, fmt.Sprintf("endpoint responded %s", resp.Status))
+	}
+	return AttemptResult{Status: StatusSucceeded, ResponseCode: resp.StatusCode, ResponseBody: body}
+}
+
+// readBody อ่าน response ไม่เกิน maxResponseBody byte (ตัดที่ขอบตัวอักษร UTF-8)
+func readBody(r io.Reader) string {
+	b, _ := io.ReadAll(io.LimitReader(r, maxResponseBody))
+	_, _ = io.Copy(io.Discard, io.LimitReader(r, 64<<10))
+	for len(b) > 0 && !utf8.Valid(b) {
+		b = b[:len(b)-1]
+	}
+	return string(b)
+}
+
+// retryDelay เริ่มที่ 10 วินาทีแล้วเพิ่มเป็นสองเท่าทุกครั้งจนถึง 6 ชั่วโมง (มี jitter ±20%)
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxRetryDelay)
+	jitter := time.Duration(rand.Int64N(int64(delay)*2/5+1)) - delay/5
+	return delay + jitter
+}
@@ -0,0 +1,367 @@
+// Package jsonschema ตรวจข้อมูล JSON กับ JSON Schema แบบย่อ (draft 2020-12 บางส่วน)
+// รองรับเฉพาะ keyword ที่ใช้กับข้อมูลโปรไฟล์: type, properties, required,
+// additionalProperties, enum, const, minLength, maxLength, pattern, format,
+// minimum, maximum, items, minItems, maxItems
+// keyword อื่นจะทำให้ Compile คืน error เพื่อไม่ให้ schema ถูกตีความผิดแบบเงียบ ๆ
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema คือ schema ที่ compile แล้ว พร้อมใช้ Validate ได้หลาย goroutine พร้อมกัน
+type Schema struct {
+	types                []string
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	noAdditional         bool
+	enum                 []any
+	constValue           any
+	hasConst             bool
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	format               string
+	minimum              *float64
+	maximum              *float64
+	items                *Schema
+	minItems             *int
+	maxItems             *int
+}
+
+// ValidationError บอกตำแหน่ง (JSON Pointer) และสาเหตุที่ข้อมูลไม่ผ่าน schema
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors รวม error ทุกจุดที่พบในการตรวจหนึ่งครั้ง
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ve := range e {
+		msgs[i] = ve.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var annotationKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true,
+	"title": true, "description": true, "default": true,
+	"examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+// Compile แปลง JSON Schema (bytes) เป็น Schema
+func Compile(data []byte) (*Schema, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("อ่าน schema: %w", err)
+	}
+	return compile(raw, "#")
+}
+
+func compile(raw any, at string) (*Schema, error) {
+	if b, ok := raw.(bool); ok {
+		if b {
+			return &Schema{}, nil
+		}
+		// false schema: ไม่ยอมรับค่าใด ๆ
+		return &Schema{enum: []any{}}, nil
+	}
+
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: schema ต้องเป็น object หรือ boolean", at)
+	}
+
+	s := &Schema{}
+	for key, value := range obj {
+		var err error
+		switch key {
+		case "type":
+			s.types, err = stringList(value)
+		case "properties":
+			props, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s/properties: ต้องเป็น object", at)
+			}
+			s.properties = make(map[string]*Schema, len(props))
+			for name, sub := range props {
+				s.properties[name], err = compile(sub, at+"/properties/"+name)
+				if err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			s.required, err = stringList(value)
+		case "additionalProperties":
+			if b, ok := value.(bool); ok {
+				s.noAdditional = !b
+				break
+			}
+			s.additionalProperties, err = compile(value, at+"/additionalProperties")
+		case "enum":
+			list, ok := value.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s/enum: ต้องเป็น array", at)
+			}
+			s.enum = list
+		case "const":
+			s.constValue, s.hasConst = value, true
+		case "minLength":
+			s.minLength, err = nonNegativeInt(value)
+		case "maxLength":
+			s.maxLength, err = nonNegativeInt(value)
+		case "pattern":
+			p, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s/pattern: ต้องเป็น string", at)
+			}
+			s.pattern, err = regexp.Compile(p)
+		case "format":
+			f, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s/format: ต้องเป็น string", at)
+			}
+			s.format = f
+		case "minimum":
+			s.minimum, err = number(value)
+		case "maximum":
+			s.maximum, err = number(value)
+		case "items":
+			s.items, err = compile(value, at+"/items")
+		case "minItems":
+			s.minItems, err = nonNegativeInt(value)
+		case "maxItems":
+			s.maxItems, err = nonNegativeInt(value)
+		default:
+			if !annotationKeywords[key] {
+				return nil, fmt.Errorf("%s: ไม่รองรับ keyword %q", at, key)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", at, key, err)
+		}
+	}
+	return s, nil
+}
+
+// Validate ตรวจค่า (ที่ได้จาก encoding/json) และคืน ValidationErrors ถ้าไม่ผ่าน
+func (s *Schema) Validate(value any) error {
+	var errs ValidationErrors
+	s.validate(value, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (s *Schema) validate(value any, path string, errs *ValidationErrors) {
+	fail := func(format string, args ...any) {
+		p := path
+		if p == "" {
+			p = "/"
+		}
+		*errs = append(*errs, ValidationError{Path: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !matchesAnyType(value, s.types) {
+		fail("ต้องเป็นชนิด %s", strings.Join(s.types, " หรือ "))
+		return
+	}
+	if s.enum != nil && !containsValue(s.enum, value) {
+		fail("ค่าต้องเป็นหนึ่งใน %s", formatValues(s.enum))
+	}
+	if s.hasConst && !equal(s.constValue, value) {
+		fail("ค่าต้องเท่ากับ %s", formatValues([]any{s.constValue}))
+	}
+
+	switch v := value.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			fail("ความยาวต้องไม่น้อยกว่า %d ตัวอักษร", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("ความยาวต้องไม่เกิน %d ตัวอักษร", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("รูปแบบไม่ตรงกับ %s", s.pattern.String())
+		}
+		if s.format != "" && !validFormat(s.format, v) {
+			fail("รูปแบบ %s ไม่ถูกต้อง", s.format)
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			fail("ค่าต้องไม่น้อยกว่า %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			fail("ค่าต้องไม่เกิน %v", *s.maximum)
+		}
+	case []any:
+		if s.minItems != nil && len(v) < *s.minItems {
+			fail("ต้องมีอย่างน้อย %d รายการ", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			fail("ต้องมีไม่เกิน %d รายการ", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, fmt.Sprintf("%s/%d", path, i), errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, ValidationError{Path: path + "/" + name, Message: "ต้องระบุค่า"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "/" + k
+			if sub, ok := s.properties[k]; ok {
+				sub.validate(v[k], child, errs)
+				continue
+			}
+			if s.noAdditional {
+				*errs = append(*errs, ValidationError{Path: child, Message: "ไม่อนุญาตให้มี field นี้"})
+				continue
+			}
+			if s.additionalProperties != nil {
+				s.additionalProperties.validate(v[k], child, errs)
+			}
+		}
+	}
+}
+
+func matchesAnyType(value any, types []string) bool {
+	for _, t := range types {
+		switch t {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		case "integer":
+			if f, ok := value.(float64); ok && f == math.Trunc(f) {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validFormat(format, value string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	default:
+		// format ที่ไม่รู้จักถือเป็น annotation ตามสเปก
+		return true
+	}
+}
+
+func containsValue(list []any, value any) bool {
+	for _, item := range list {
+		if equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	ab, errA := json.Marshal(a)
+	bb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ab) == string(bb)
+}
+
+func formatValues(values []any) string {
+	b, _ := json.Marshal(values)
+	return string(b)
+}
+
+func stringList(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, errors.New("ต้องเป็น string หรือ array ของ string")
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, errors.New("ต้องเป็น string หรือ array ของ string")
+	}
+}
+
+func number(value any) (*float64, error) {
+	f, ok := value.(float64)
+	if !ok {
+		return nil, errors.New("ต้องเป็นตัวเลข")
+	}
+	return &f, nil
+}
+
+func nonNegativeInt(value any) (*int, error) {
+	f, ok := value.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, errors.New("ต้องเป็นจำนวนเต็มไม่ติดลบ")
+	}
+	n := int(f)
+	return &n, nil
+}
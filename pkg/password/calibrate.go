@@ -0,0 +1,55 @@
+package password
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// minCalibrateMemory คือหน่วยความจำต่ำสุดที่ Calibrate จะลดลงไปถึง (19 MiB ตามขั้นต่ำของ OWASP สำหรับ Argon2id)
+const minCalibrateMemory = 19 * 1024
+
+// Calibrate หาค่า Params ที่ hash หนึ่งครั้งบนเครื่องนี้ใช้เวลาใกล้ target ที่สุดโดยไม่เกิน
+// เริ่มจาก memory (KiB) และ threads ที่ให้มากับ time=1 แล้วเพิ่ม time ทีละรอบจนเกิน target (ไม่เกิน maxArgon2Time)
+// ถ้า time=1 ก็ยังช้าเกินจะลด memory ลงครึ่งหนึ่งจนถึง 19 MiB คืนค่าที่เลือกพร้อมเวลาที่วัดได้
+// รันตรงไม่ผ่าน Limits จึงควรเรียกจาก CLI บนเครื่องที่ไม่มีงานอื่นเท่านั้น
+func Calibrate(target time.Duration, memory uint32, threads uint8) (Params, time.Duration, error) {
+	p := Params{Time: 1, Memory: memory, Threads: threads}
+	if err := p.Validate(); err != nil {
+		return Params{}, 0, err
+	}
+	if target <= 0 {
+		return Params{}, 0, errors.New("target ต้องมากกว่า 0")
+	}
+
+	elapsed := measure(p)
+	for elapsed > target && p.Memory/2 >= minCalibrateMemory {
+		p.Memory /= 2
+		elapsed = measure(p)
+	}
+	for p.Time < maxArgon2Time {
+		next := p
+		next.Time++
+		took := measure(next)
+		if took > target {
+			return p, elapsed, nil
+		}
+		p, elapsed = next, took
+	}
+	return p, elapsed, nil
+}
+
+// measure คืนเวลาที่ใช้ hash หนึ่งครั้ง (เอาค่าน้อยสุดจากสามครั้งเพื่อตัดสัญญาณรบกวน)
+func measure(p Params) time.Duration {
+	salt := make([]byte, argonSaltLen)
+	best := time.Duration(-1)
+	for range 3 {
+		start := time.Now()
+		argon2.IDKey([]byte("calibrate"), salt, p.Time, p.Memory, p.Threads, argonKeyLen)
+		if took := time.Since(start); best < 0 || took < best {
+			best = took
+		}
+	}
+	return best
+}
@@ -0,0 +1,142 @@
+package password
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadParams อ่านความเข้มของ Argon2 จาก env ค่าที่ไม่ได้ตั้งใช้ตาม DefaultParams
+//
+//	ARGON2_TIME        จำนวนรอบ (default 3, ไม่เกิน 64)
+//	ARGON2_MEMORY_KIB  หน่วยความจำต่อ hash เป็น KiB (default 65536 = 64 MiB, ไม่เกิน 1 GiB)
+//	ARGON2_THREADS     จำนวน thread ต่อ hash (default 2)
+//
+// หาค่าที่เหมาะกับเครื่องได้ด้วย userctl calibrate
+func LoadParams() (Params, error) {
+	p := DefaultParams
+	var errs []error
+
+	readUint := func(key string, bits int) (uint64, bool) {
+		raw := os.Getenv(key)
+		if raw == "" {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(raw, 10, bits)
+		if err != nil || v == 0 {
+			errs = append(errs, fmt.Errorf("%s ต้องเป็นจำนวนเต็มบวก (ได้ %q)", key, raw))
+			return 0, false
+		}
+		return v, true
+	}
+	if v, ok := readUint("ARGON2_TIME", 32); ok {
+		p.Time = uint32(v)
+	}
+	if v, ok := readUint("ARGON2_MEMORY_KIB", 32); ok {
+		p.Memory = uint32(v)
+	}
+	if v, ok := readUint("ARGON2_THREADS", 8); ok {
+		p.Threads = uint8(v)
+	}
+	if len(errs) == 0 {
+		if err := p.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Params{}, err
+	}
+	return p, nil
+}
+
+// LoadPeppers อ่าน pepper จาก PASSWORD_PEPPER หรือจากไฟล์ที่ PASSWORD_PEPPER_FILE ชี้ (เช่น Docker/Kubernetes secret) ตั้งได้อย่างใดอย่างหนึ่ง
+// แต่ละตัวเขียนเป็น <id>:<secret base64> คั่นด้วยจุลภาคหรือขึ้นบรรทัดใหม่ บรรทัดที่ขึ้นต้นด้วย # ถูกข้าม
+// ตัวแรกใช้กับ hash ใหม่ ตัวที่เหลือใช้ตรวจ hash เดิม ไม่ได้ตั้งทั้งคู่ = ไม่ใช้ pepper
+func LoadPeppers() ([]Pepper, error) {
+	raw := os.Getenv("PASSWORD_PEPPER")
+	if path := os.Getenv("PASSWORD_PEPPER_FILE"); path != "" {
+		if raw != "" {
+			return nil, errors.New("ตั้ง PASSWORD_PEPPER กับ PASSWORD_PEPPER_FILE พร้อมกันไม่ได้")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("อ่าน PASSWORD_PEPPER_FILE: %w", err)
+		}
+		raw = string(data)
+	}
+
+	var list []Pepper
+	var errs []error
+	entries := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '\n' })
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok {
+			errs = append(errs, fmt.Errorf("pepper รายการที่ %d ต้องอยู่ในรูป <id>:<secret base64>", i+1))
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(secret))
+		if err != nil {
+			key, err = base64.RawStdEncoding.DecodeString(strings.TrimSpace(secret))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pepper %s: secret ต้องเป็น base64", id))
+			continue
+		}
+		list = append(list, Pepper{ID: strings.TrimSpace(id), Key: key})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// LoadLimits อ่านโควตาของ Argon2 จาก env ค่าที่ไม่ได้ตั้งใช้ตาม DefaultLimits
+//
+//	PASSWORD_HASH_CONCURRENCY  จำนวน hash ที่รันพร้อมกัน (default คำนวณจากหน่วยความจำของ cgroup)
+//	PASSWORD_HASH_QUEUE        จำนวนคำขอที่รอคิวได้ (default 16 เท่าของ concurrency)
+//	PASSWORD_HASH_MAX_WAIT     เวลารอคิวนานที่สุดก่อนตอบ 503 (default 3s)
+func LoadLimits() (Limits, error) {
+	l := DefaultLimits()
+	var errs []error
+
+	readInt := func(key string, dst *int, minimum int) bool {
+		raw := os.Getenv(key)
+		if raw == "" {
+			return false
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < minimum {
+			errs = append(errs, fmt.Errorf("%s ต้องเป็นจำนวนเต็มไม่น้อยกว่า %d (ได้ %q)", key, minimum, raw))
+			return false
+		}
+		*dst = v
+		return true
+	}
+	concurrencySet := readInt("PASSWORD_HASH_CONCURRENCY", &l.Concurrency, 1)
+	if !readInt("PASSWORD_HASH_QUEUE", &l.Queue, 0) && concurrencySet {
+		l.Queue = 16 * l.Concurrency
+	}
+	if raw := os.Getenv("PASSWORD_HASH_MAX_WAIT"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v < 0 {
+			errs = append(errs, fmt.Errorf("PASSWORD_HASH_MAX_WAIT ต้องเป็นช่วงเวลา เช่น 500ms หรือ 3s (ได้ %q)", raw))
+		} else {
+			l.MaxWait = v
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Limits{}, err
+	}
+	return l, nil
+}
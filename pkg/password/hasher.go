@@ -0,0 +1,107 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Hasher คืออัลกอริทึมที่ CheckPassword ใช้ตรวจ hash ได้ เลือกจากคำนำหน้าของ hash
+// hash ใหม่สร้างด้วย Argon2id เสมอ hasher อื่นมีไว้ตรวจ hash ที่ย้ายมาจากระบบเดิมจนกว่าจะถูก rehash
+type Hasher interface {
+	// Name คือชื่ออัลกอริทึมที่ Identify คืน เช่น "bcrypt"
+	Name() string
+	// Prefixes คือคำนำหน้าของ hash ที่ hasher นี้อ่านได้
+	Prefixes() []string
+	// Parse ตรวจว่า hash อยู่ในรูปแบบที่ Verify ใช้ได้ โดยไม่ต้องรู้รหัสผ่าน
+	Parse(hash string) error
+	// Verify คืน ErrMismatch ถ้ารหัสผ่านไม่ตรง error อื่นหมายถึง hash เสีย
+	Verify(hash, raw string) error
+}
+
+// MemoryCoster เป็น interface เสริมของ Hasher ที่ Verify ใช้หน่วยความจำมาก (เช่น scrypt)
+// CheckPassword จองช่องใน pool ตาม MemoryCost (ไบต์) hasher ที่ไม่ได้ implement นับเป็นหนึ่งช่อง
+type MemoryCoster interface {
+	MemoryCost(hash string) uint64
+}
+
+var (
+	hashersMu sync.RWMutex
+	hashers   []Hasher
+)
+
+// Register เพิ่ม hasher เข้า registry ถ้าคำนำหน้าซ้ำกับที่มีอยู่ ตัวที่คำนำหน้ายาวกว่าจะถูกเลือก
+func Register(h Hasher) {
+	hashersMu.Lock()
+	defer hashersMu.Unlock()
+	hashers = append(hashers, h)
+}
+
+func init() {
+	Register(argon2Hasher{})
+	Register(bcryptHasher{})
+	Register(scryptHasher{})
+	Register(pbkdf2Hasher{})
+}
+
+// lookup หา hasher ที่คำนำหน้าตรงกับ hash ยาวที่สุด
+func lookup(hash string) (Hasher, bool) {
+	hashersMu.RLock()
+	defer hashersMu.RUnlock()
+	var found Hasher
+	longest := 0
+	for _, h := range hashers {
+		for _, prefix := range h.Prefixes() {
+			if len(prefix) > longest && strings.HasPrefix(hash, prefix) {
+				found, longest = h, len(prefix)
+			}
+		}
+	}
+	return found, found != nil
+}
+
+// CheckPassword เปรียบเทียบรหัสผ่าน (หลัง SHA-256 จาก client) กับ hash ที่เก็บในฐานข้อมูล ตามอัลกอริทึมของ hash นั้น
+// คืน ErrMismatch ถ้าไม่ตรง และรอคิวแบบเดียวกับ HashPassword
+func CheckPassword(ctx context.Context, hash, raw string) error {
+	h, ok := lookup(hash)
+	if !ok {
+		return errors.New("hash ไม่รองรับอัลกอริทึมนี้")
+	}
+
+	var memory uint64
+	if c, ok := h.(MemoryCoster); ok {
+		memory = c.MemoryCost(hash)
+	}
+	release, err := acquire(ctx, memory)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return h.Verify(hash, raw)
+}
+
+// NeedsRehash บอกว่าควร hash ใหม่ด้วยรหัสผ่านที่เพิ่งตรวจผ่านแล้วบันทึกทับ
+// ได้ true เมื่อ hash ไม่ใช่ Argon2id, อ่อนกว่า CurrentParams (time, memory หรือความยาว key น้อยกว่า),
+// ใช้ pepper คนละตัวกับตัวปัจจุบัน (หมุน key แบบค่อยเป็นค่อยไปตอนล็อกอิน) หรืออ่านไม่ออก
+// threads ไม่นับเพราะไม่ได้ทำให้เดายากขึ้น
+func NeedsRehash(hash string) bool {
+	h, err := parseArgon2(hash)
+	if err != nil {
+		return true
+	}
+	p := CurrentParams()
+	return h.params.Time < p.Time || h.params.Memory < p.Memory || uint32(len(h.key)) < argonKeyLen ||
+		h.keyID != currentPepperID()
+}
+
+// Identify บอกชื่ออัลกอริทึมของ hash ที่เก็บไว้ ใช้ตรวจ hash ที่นำเข้าจากระบบอื่นก่อนบันทึก
+// ผลลัพธ์เป็นชื่อของ hasher ที่ลงทะเบียนไว้ ("argon2id", "bcrypt", "scrypt" หรือ "pbkdf2-sha256")
+// ถ้าไม่รู้จักหรือรูปแบบเสียจะคืน false
+func Identify(hash string) (string, bool) {
+	h, ok := lookup(hash)
+	if !ok || h.Parse(hash) != nil {
+		return "", false
+	}
+	return h.Name(), true
+}
@@ -0,0 +1,129 @@
+package password_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"fristGoproject/pkg/password"
+)
+
+// testParams คือค่า Argon2 ที่เบาพอให้เทสต์เร็ว
+var testParams = password.Params{Time: 1, Memory: 64, Threads: 1}
+
+// useParams ตั้งค่า Argon2 ระหว่างเทสต์แล้วคืนค่าเดิมตอนจบ
+func useParams(t *testing.T, p password.Params) {
+	t.Helper()
+	old := password.CurrentParams()
+	password.SetParams(p)
+	t.Cleanup(func() { password.SetParams(old) })
+}
+
+func mustHash(t *testing.T, raw string) string {
+	t.Helper()
+	hash, err := password.HashPassword(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	return hash
+}
+
+func b64(n int) string {
+	return base64.RawStdEncoding.EncodeToString([]byte(strings.Repeat("k", n)))
+}
+
+func TestCheckPasswordKnownAnswers(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+		raw  string
+	}{
+		// ชุดทดสอบของ OpenBSD bcrypt
+		{"bcrypt", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", "U*U"},
+		// ตัวอย่างจากเอกสารของ passlib
+		{"scrypt", "$scrypt$ln=16,r=8,p=1$aM15713r3Xsvxbi31lqr1Q$nFNh2CVHVjNldFVKDHDlm4CbdRSCdEBsjjJxD+iCs5E", "password"},
+		{"pbkdf2-sha256", "$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M", "password"},
+	}
+	ctx := context.Background()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if name, ok := password.Identify(tc.hash); !ok || name != tc.name {
+				t.Errorf("Identify = (%q, %v), want (%q, true)", name, ok, tc.name)
+			}
+			if err := password.CheckPassword(ctx, tc.hash, tc.raw); err != nil {
+				t.Errorf("CheckPassword(correct) = %v, want nil", err)
+			}
+			if err := password.CheckPassword(ctx, tc.hash, tc.raw+"x"); !errors.Is(err, password.ErrMismatch) {
+				t.Errorf("CheckPassword(wrong) = %v, want ErrMismatch", err)
+			}
+			if !password.NeedsRehash(tc.hash) {
+				t.Error("NeedsRehash = false, want true for non-argon2 hash")
+			}
+		})
+	}
+}
+
+func TestIdentifyRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"empty", ""},
+		{"unknown prefix", "$md5$abc"},
+		{"argon2 missing parts", "$argon2id$v=19$m=64,t=1,p=1$" + b64(16)},
+		{"argon2 wrong version", "$argon2id$v=16$m=64,t=1,p=1$" + b64(16) + "$" + b64(32)},
+		{"argon2 unknown param", "$argon2id$v=19$m=64,t=1,x=1$" + b64(16) + "$" + b64(32)},
+		{"argon2 empty key", "$argon2id$v=19$m=64,t=1,p=1$" + b64(16) + "$"},
+		{"argon2 short key", "$argon2id$v=19$m=64,t=1,p=1$" + b64(16) + "$" + b64(8)},
+		{"argon2 empty salt", "$argon2id$v=19$m=64,t=1,p=1$$" + b64(32)},
+		{"argon2 short salt", "$argon2id$v=19$m=64,t=1,p=1$" + b64(4) + "$" + b64(32)},
+		{"argon2 huge memory", "$argon2id$v=19$m=4294967295,t=1,p=1$" + b64(16) + "$" + b64(32)},
+		{"argon2 huge time", "$argon2id$v=19$m=64,t=100000,p=1$" + b64(16) + "$" + b64(32)},
+		{"bcrypt truncated", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyO"},
+		{"scrypt huge memory", "$scrypt$ln=30,r=8,p=1$" + b64(16) + "$" + b64(32)},
+		{"scrypt missing r", "$scrypt$ln=14,p=1$" + b64(16) + "$" + b64(32)},
+		{"scrypt short key", "$scrypt$ln=14,r=8,p=1$" + b64(16) + "$" + b64(8)},
+		{"pbkdf2 too many rounds", "$pbkdf2-sha256$100000000$" + b64(16) + "$" + b64(32)},
+		{"pbkdf2 empty salt", "$pbkdf2-sha256$6400$$" + b64(32)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if name, ok := password.Identify(tc.hash); ok {
+				t.Errorf("Identify = (%q, true), want false", name)
+			}
+			if err := password.CheckPassword(context.Background(), tc.hash, "password"); err == nil || errors.Is(err, password.ErrMismatch) {
+				t.Errorf("CheckPassword = %v, want a format error", err)
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	useParams(t, testParams)
+	current := mustHash(t, "password")
+
+	tests := []struct {
+		name   string
+		params password.Params
+		hash   string
+		want   bool
+	}{
+		{"current", testParams, current, false},
+		{"more threads only", password.Params{Time: 1, Memory: 64, Threads: 2}, current, false},
+		{"weaker time", password.Params{Time: 2, Memory: 64, Threads: 1}, current, true},
+		{"weaker memory", password.Params{Time: 1, Memory: 128, Threads: 1}, current, true},
+		{"short key", testParams, "$argon2id$v=19$m=64,t=1,p=1$" + b64(16) + "$" + b64(16), true},
+		{"bcrypt", testParams, "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", true},
+		{"unreadable", testParams, "garbage", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			useParams(t, tc.params)
+			if got := password.NeedsRehash(tc.hash); got != tc.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
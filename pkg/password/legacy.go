@@ -0,0 +1,200 @@
+package password
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// hasher ของ hash ที่ย้ายมาจากระบบเดิม ใช้ตรวจอย่างเดียว NeedsRehash จะขอให้ hash ใหม่เป็น Argon2id เสมอ
+// Verify เทียบกับค่าที่ client ส่ง (sha256_hex(password)) ไม่ใช่รหัสผ่านจริง
+// hash ที่ระบบเดิมคำนวณจาก plain text จึงตรวจไม่ผ่านเสมอ ต้องให้ผู้นำเข้าใช้เฉพาะ hash ของ sha256_hex(password)
+
+const (
+	// maxScryptMemory คือหน่วยความจำสูงสุดที่ยอมให้ scrypt หนึ่งครั้งใช้ (128·r·N ไบต์) กัน hash ที่ตั้งค่าสูงผิดปกติ
+	maxScryptMemory = 1 << 30
+	// maxPBKDF2Rounds คือจำนวนรอบสูงสุดของ PBKDF2 ที่ยอมตรวจ
+	maxPBKDF2Rounds = 10_000_000
+	// minLegacyKeyLen คือความยาว key ขั้นต่ำ (ไบต์) ของ Argon2, scrypt และ PBKDF2
+	minLegacyKeyLen = 16
+	// maxArgon2Memory คือ m สูงสุด (KiB) ที่ยอมทั้งใน hash ที่เก็บไว้และ ARGON2_MEMORY_KIB (1 GiB เท่ากับ maxScryptMemory)
+	maxArgon2Memory = 1 << 20
+	// maxArgon2Time คือ t สูงสุดที่ยอม กัน hash ที่ถือช่องของ pool ไว้นานผิดปกติ
+	maxArgon2Time = 64
+	// minArgon2SaltLen คือความยาว salt ขั้นต่ำ (ไบต์) ของ Argon2 ตามขั้นต่ำของ RFC 9106
+	minArgon2SaltLen = 8
+)
+
+// bcryptHasher ตรวจ hash รูป $2a$, $2b$ หรือ $2y$
+type bcryptHasher struct{}
+
+func (bcryptHasher) Name() string { return "bcrypt" }
+
+func (bcryptHasher) Prefixes() []string { return []string{"$2a$", "$2b$", "$2y$"} }
+
+func (bcryptHasher) Parse(hash string) error {
+	if len(hash) != 60 {
+		return errors.New("รูปแบบ hash bcrypt ไม่ถูกต้อง")
+	}
+	_, err := bcrypt.Cost([]byte(hash))
+	return err
+}
+
+func (bcryptHasher) Verify(hash, raw string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(raw))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
+
+// scryptHasher ตรวจ hash รูป $scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<key> (แบบ passlib)
+type scryptHasher struct{}
+
+func (scryptHasher) Name() string { return "scrypt" }
+
+func (scryptHasher) Prefixes() []string { return []string{"$scrypt$"} }
+
+func (scryptHasher) Parse(hash string) error {
+	_, err := parseScrypt(hash)
+	return err
+}
+
+func (scryptHasher) MemoryCost(hash string) uint64 {
+	h, err := parseScrypt(hash)
+	if err != nil {
+		return 0
+	}
+	return 128 * uint64(h.r) << h.logN
+}
+
+func (scryptHasher) Verify(hash, raw string) error {
+	h, err := parseScrypt(hash)
+	if err != nil {
+		return err
+	}
+	key, err := scrypt.Key([]byte(raw), h.salt, 1<<h.logN, h.r, h.p, len(h.key))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, h.key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+type scryptHash struct {
+	logN, r, p int
+	salt, key  []byte
+}
+
+func parseScrypt(hash string) (scryptHash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != "scrypt" {
+		return scryptHash{}, errors.New("รูปแบบ hash scrypt ไม่ถูกต้อง")
+	}
+
+	var h scryptHash
+	for _, param := range strings.Split(parts[2], ",") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return scryptHash{}, errors.New("รูปแบบพารามิเตอร์ scrypt ไม่ถูกต้อง")
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return scryptHash{}, fmt.Errorf("อ่านค่า %s: ต้องเป็นจำนวนเต็มบวก", name)
+		}
+		switch name {
+		case "ln":
+			h.logN = n
+		case "r":
+			h.r = n
+		case "p":
+			h.p = n
+		default:
+			return scryptHash{}, fmt.Errorf("พารามิเตอร์ไม่รองรับ: %s", name)
+		}
+	}
+	if h.logN == 0 || h.r == 0 || h.p == 0 {
+		return scryptHash{}, errors.New("hash scrypt ต้องมี ln, r และ p")
+	}
+	if h.logN > 30 || 128*h.r<<h.logN > maxScryptMemory || h.p > 16 {
+		return scryptHash{}, errors.New("พารามิเตอร์ scrypt สูงเกินกว่าที่ตรวจได้")
+	}
+
+	var err error
+	if h.salt, err = decodeAB64(parts[3]); err != nil || len(h.salt) == 0 {
+		return scryptHash{}, errors.New("ถอดรหัส salt ไม่ได้")
+	}
+	if h.key, err = decodeAB64(parts[4]); err != nil || len(h.key) < minLegacyKeyLen {
+		return scryptHash{}, errors.New("ถอดรหัส key ไม่ได้")
+	}
+	return h, nil
+}
+
+// pbkdf2Hasher ตรวจ hash รูป $pbkdf2-sha256$<rounds>$<salt>$<key> (แบบ passlib)
+type pbkdf2Hasher struct{}
+
+func (pbkdf2Hasher) Name() string { return "pbkdf2-sha256" }
+
+func (pbkdf2Hasher) Prefixes() []string { return []string{"$pbkdf2-sha256$"} }
+
+func (pbkdf2Hasher) Parse(hash string) error {
+	_, err := parsePBKDF2(hash)
+	return err
+}
+
+func (pbkdf2Hasher) Verify(hash, raw string) error {
+	h, err := parsePBKDF2(hash)
+	if err != nil {
+		return err
+	}
+	key, err := pbkdf2.Key(sha256.New, raw, h.salt, h.rounds, len(h.key))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, h.key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+type pbkdf2Hash struct {
+	rounds    int
+	salt, key []byte
+}
+
+func parsePBKDF2(hash string) (pbkdf2Hash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != "pbkdf2-sha256" {
+		return pbkdf2Hash{}, errors.New("รูปแบบ hash pbkdf2-sha256 ไม่ถูกต้อง")
+	}
+
+	var h pbkdf2Hash
+	var err error
+	h.rounds, err = strconv.Atoi(strings.TrimPrefix(parts[2], "i="))
+	if err != nil || h.rounds < 1 || h.rounds > maxPBKDF2Rounds {
+		return pbkdf2Hash{}, fmt.Errorf("จำนวนรอบ PBKDF2 ต้องอยู่ระหว่าง 1 ถึง %d", maxPBKDF2Rounds)
+	}
+	if h.salt, err = decodeAB64(parts[3]); err != nil || len(h.salt) == 0 {
+		return pbkdf2Hash{}, errors.New("ถอดรหัส salt ไม่ได้")
+	}
+	if h.key, err = decodeAB64(parts[4]); err != nil || len(h.key) < minLegacyKeyLen {
+		return pbkdf2Hash{}, errors.New("ถอดรหัส key ไม่ได้")
+	}
+	return h, nil
+}
+
+// decodeAB64 ถอด base64 แบบที่ passlib ใช้ ("." แทน "+" ไม่มี padding) และรับ base64 ปกติด้วย
+func decodeAB64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.ReplaceAll(s, ".", "+"), "=")
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,55 @@
+package password
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// availableMemory คืนหน่วยความจำที่ process ใช้ได้เป็นไบต์ คืน 0 ถ้าหาไม่ได้ (เช่นไม่ใช่ Linux)
+// อ่าน limit ของ cgroup v2 แล้ว v1 ก่อน เพราะใน container ค่า MemTotal คือหน่วยความจำของทั้งเครื่อง
+func availableMemory() uint64 {
+	if v, ok := readCgroupLimit("/sys/fs/cgroup/memory.max"); ok {
+		return v
+	}
+	if v, ok := readCgroupLimit("/sys/fs/cgroup/memory/memory.limit_in_bytes"); ok {
+		return v
+	}
+	return memTotal()
+}
+
+// readCgroupLimit อ่านไฟล์ limit ของ cgroup คืน false ถ้าไม่มีไฟล์หรือไม่ได้จำกัด
+// ("max" ใน v2 หรือค่าใหญ่มากใน v1)
+func readCgroupLimit(path string) (uint64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || v == 0 || v >= 1<<62 {
+		return 0, false
+	}
+	return v, true
+}
+
+// memTotal อ่าน MemTotal จาก /proc/meminfo
+func memTotal() uint64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
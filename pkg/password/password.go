@@ -1,6 +1,7 @@
 package password
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
@@ -8,89 +9,216 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"golang.org/x/crypto/argon2"
 )
 
 const (
-	argonTime    uint32 = 3
-	argonMemory  uint32 = 64 * 1024
-	argonThreads uint8  = 2
 	argonKeyLen  uint32 = 32
 	argonSaltLen        = 16
 )
 
+// คำนำหน้าของ hash Argon2id HashPassword สร้างรูป PHC มาตรฐาน ($argon2id$...) ที่ libsodium, passlib ฯลฯ ตรวจได้
+// รูปเดิมของระบบนี้ไม่มี $ นำหน้า ส่วนที่เหลือเหมือนกันทุกไบต์ จึงแปลงได้ด้วยการเติม $ โดยไม่ต้องรู้รหัสผ่าน
+const (
+	Argon2Prefix       = "$argon2id$"
+	LegacyArgon2Prefix = "argon2id$"
+)
+
+// Params คือความเข้มของ Argon2id ที่ใช้กับ hash ใหม่ Memory มีหน่วยเป็น KiB
+type Params struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// DefaultParams คือค่าเริ่มต้น (t=3, 64 MiB, p=2) ปรับให้เข้ากับเครื่องด้วย Calibrate
+var DefaultParams = Params{Time: 3, Memory: 64 * 1024, Threads: 2}
+
+// Validate ตรวจว่า Argon2 รับค่านี้ได้ (Memory ต้องไม่น้อยกว่า 8 KiB ต่อ thread)
+// และไม่เกินเพดาน maxArgon2Time/maxArgon2Memory ที่ใช้กับทั้งค่าตั้งและ hash ที่เก็บไว้
+func (p Params) Validate() error {
+	switch {
+	case p.Time < 1:
+		return errors.New("Argon2 time ต้องไม่น้อยกว่า 1")
+	case p.Time > maxArgon2Time:
+		return fmt.Errorf("Argon2 time ต้องไม่เกิน %d", maxArgon2Time)
+	case p.Memory > maxArgon2Memory:
+		return fmt.Errorf("Argon2 memory ต้องไม่เกิน %d KiB", maxArgon2Memory)
+	case p.Threads < 1:
+		return errors.New("Argon2 threads ต้องไม่น้อยกว่า 1")
+	case p.Memory < 8*uint32(p.Threads):
+		return fmt.Errorf("Argon2 memory ต้องไม่น้อยกว่า %d KiB (8 KiB ต่อ thread)", 8*uint32(p.Threads))
+	}
+	return nil
+}
+
+// params คือค่าที่ SetParams ตั้งไว้ (nil = DefaultParams)
+var params atomic.Pointer[Params]
+
+// SetParams เปลี่ยนความเข้มของ hash ใหม่ hash เดิมยังตรวจได้ตามค่าที่ฝังไว้ และ NeedsRehash จะเทียบกับค่านี้
+// ควรเรียกก่อน SetLimits เพราะ DefaultLimits คิดจาก Memory ของค่านี้
+func SetParams(p Params) {
+	params.Store(&p)
+}
+
+// CurrentParams คืนความเข้มที่ใช้กับ hash ใหม่
+func CurrentParams() Params {
+	if p := params.Load(); p != nil {
+		return *p
+	}
+	return DefaultParams
+}
+
+// ErrMismatch คือรหัสผ่านไม่ตรงกับ hash error อื่นจาก CheckPassword หมายถึงตรวจไม่ได้ (hash เสีย, ErrBusy, ErrUnknownPepper, ctx ถูกยกเลิก)
+var ErrMismatch = errors.New("hash ไม่ตรง")
+
 // HashPassword ใช้ Argon2id แปลงรหัสผ่านที่ client ส่งมา (หลัง SHA-256) ให้เป็น hash สำหรับเก็บในฐาน
-func HashPassword(raw string) (string, error) {
+// ใช้ค่าจาก CurrentParams และ pepper ตัวปัจจุบัน (ถ้าตั้งไว้) รอคิวตาม Limits ก่อนรัน คืน ErrBusy หรือ ctx.Err() ถ้าไม่ได้คิว
+func HashPassword(ctx context.Context, raw string) (string, error) {
+	p := CurrentParams()
+	input, keyID := []byte(raw), ""
+	if pepper := currentPepper(); pepper != nil {
+		input, keyID = peppered(pepper.Key, raw), ",keyid="+pepper.ID
+	}
 	salt := make([]byte, argonSaltLen)
 	if _, err := rand.Read(salt); err != nil {
 		return "", fmt.Errorf("สร้าง salt: %w", err)
 	}
 
-	key := argon2.IDKey([]byte(raw), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	release, err := acquire(ctx, uint64(p.Memory)*1024)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	key := argon2.IDKey(input, salt, p.Time, p.Memory, p.Threads, argonKeyLen)
 
 	encodedSalt := base64.RawStdEncoding.EncodeToString(salt)
 	encodedKey := base64.RawStdEncoding.EncodeToString(key)
 
-	hash := fmt.Sprintf("argon2id$v=19$m=%d,t=%d,p=%d$%s$%s", argonMemory, argonTime, argonThreads, encodedSalt, encodedKey)
+	hash := fmt.Sprintf(Argon2Prefix+"v=19$m=%d,t=%d,p=%d%s$%s$%s", p.Memory, p.Time, p.Threads, keyID, encodedSalt, encodedKey)
 	return hash, nil
 }
 
-// CheckPassword เปรียบเทียบรหัสผ่าน (หลัง SHA-256 จาก client) กับ hash ที่เก็บในฐานข้อมูล
-func CheckPassword(hash, raw string) error {
-	parts := strings.Split(hash, "$")
+// argon2Hasher ตรวจ hash ที่ HashPassword สร้าง ทั้งรูป PHC และรูปเดิม
+type argon2Hasher struct{}
+
+func (argon2Hasher) Name() string { return "argon2id" }
+
+func (argon2Hasher) Prefixes() []string { return []string{Argon2Prefix, LegacyArgon2Prefix} }
+
+func (argon2Hasher) Parse(hash string) error {
+	h, err := parseArgon2(hash)
+	if err != nil {
+		return err
+	}
+	_, err = pepperKey(h.keyID)
+	return err
+}
+
+func (argon2Hasher) MemoryCost(hash string) uint64 {
+	h, err := parseArgon2(hash)
+	if err != nil {
+		return 0
+	}
+	return uint64(h.params.Memory) * 1024
+}
+
+func (argon2Hasher) Verify(hash, raw string) error {
+	h, err := parseArgon2(hash)
+	if err != nil {
+		return err
+	}
+	pepper, err := pepperKey(h.keyID)
+	if err != nil {
+		return err
+	}
+	derivedKey := argon2.IDKey(peppered(pepper, raw), h.salt, h.params.Time, h.params.Memory, h.params.Threads, uint32(len(h.key)))
+	if subtle.ConstantTimeCompare(derivedKey, h.key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// argon2Hash คือ hash ที่แยกส่วนแล้ว keyID ว่างถ้า hash ไม่ใช้ pepper
+type argon2Hash struct {
+	params Params
+	keyID  string
+	salt   []byte
+	key    []byte
+}
+
+// parseArgon2 อ่าน hash รูป $argon2id$v=19$m=<KiB>,t=<time>,p=<threads>[,keyid=<pepper>]$<salt>$<key> ($ ตัวแรกไม่บังคับ)
+func parseArgon2(hash string) (argon2Hash, error) {
+	parts := strings.Split(strings.TrimPrefix(hash, "$"), "$")
 	if len(parts) != 5 {
-		return errors.New("รูปแบบ hash ไม่ถูกต้อง")
+		return argon2Hash{}, errors.New("รูปแบบ hash ไม่ถูกต้อง")
 	}
 
 	if parts[0] != "argon2id" || parts[1] != "v=19" {
-		return errors.New("hash ไม่รองรับอัลกอริทึมนี้")
+		return argon2Hash{}, errors.New("hash ไม่รองรับอัลกอริทึมนี้")
 	}
 
-	paramsPart := parts[2]
-	paramValues := strings.Split(paramsPart, ",")
-	if len(paramValues) != 3 {
-		return errors.New("รูปแบบพารามิเตอร์ Argon2 ไม่ถูกต้อง")
+	paramValues := strings.Split(parts[2], ",")
+	if len(paramValues) != 3 && len(paramValues) != 4 {
+		return argon2Hash{}, errors.New("รูปแบบพารามิเตอร์ Argon2 ไม่ถูกต้อง")
 	}
 
-	var memory uint64
-	var time uint64
-	var threads uint64
+	var h argon2Hash
 	for _, param := range paramValues {
 		keyValue := strings.SplitN(param, "=", 2)
 		if len(keyValue) != 2 {
-			return errors.New("รูปแบบพารามิเตอร์ Argon2 ไม่ถูกต้อง")
+			return argon2Hash{}, errors.New("รูปแบบพารามิเตอร์ Argon2 ไม่ถูกต้อง")
+		}
+		if keyValue[0] == "keyid" {
+			if err := validPepperID(keyValue[1]); err != nil {
+				return argon2Hash{}, err
+			}
+			h.keyID = keyValue[1]
+			continue
+		}
+		bits := 32
+		if keyValue[0] == "p" {
+			bits = 8
 		}
-		value, err := strconv.ParseUint(keyValue[1], 10, 32)
+		value, err := strconv.ParseUint(keyValue[1], 10, bits)
 		if err != nil {
-			return fmt.Errorf("อ่านค่า %s: %w", keyValue[0], err)
+			return argon2Hash{}, fmt.Errorf("อ่านค่า %s: %w", keyValue[0], err)
 		}
 		switch keyValue[0] {
 		case "m":
-			memory = value
+			h.params.Memory = uint32(value)
 		case "t":
-			time = value
+			h.params.Time = uint32(value)
 		case "p":
-			threads = value
+			h.params.Threads = uint8(value)
 		default:
-			return fmt.Errorf("พารามิเตอร์ไม่รองรับ: %s", keyValue[0])
+			return argon2Hash{}, fmt.Errorf("พารามิเตอร์ไม่รองรับ: %s", keyValue[0])
 		}
 	}
+	if len(paramValues) == 4 && h.keyID == "" {
+		return argon2Hash{}, errors.New("รูปแบบพารามิเตอร์ Argon2 ไม่ถูกต้อง")
+	}
+	if err := h.params.Validate(); err != nil {
+		return argon2Hash{}, err
+	}
 
-	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	var err error
+	h.salt, err = base64.RawStdEncoding.DecodeString(parts[3])
 	if err != nil {
-		return fmt.Errorf("ถอดรหัส salt: %w", err)
+		return argon2Hash{}, fmt.Errorf("ถอดรหัส salt: %w", err)
+	}
+	if len(h.salt) < minArgon2SaltLen {
+		return argon2Hash{}, fmt.Errorf("salt ต้องยาวอย่างน้อย %d ไบต์", minArgon2SaltLen)
 	}
 
-	expectedKey, err := base64.RawStdEncoding.DecodeString(parts[4])
+	h.key, err = base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return fmt.Errorf("ถอดรหัส key: %w", err)
+		return argon2Hash{}, fmt.Errorf("ถอดรหัส key: %w", err)
 	}
-
-	derivedKey := argon2.IDKey([]byte(raw), salt, uint32(time), uint32(memory), uint8(threads), uint32(len(expectedKey)))
-
-	if subtle.ConstantTimeCompare(derivedKey, expectedKey) != 1 {
-		return errors.New("hash ไม่ตรง")
+	if len(h.key) < minLegacyKeyLen {
+		return argon2Hash{}, fmt.Errorf("key ต้องยาวอย่างน้อย %d ไบต์", minLegacyKeyLen)
 	}
-	return nil
+	return h, nil
 }
@@ -0,0 +1,54 @@
+package password_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"fristGoproject/pkg/password"
+)
+
+func TestHashPasswordPHCRoundTrip(t *testing.T) {
+	useParams(t, testParams)
+	ctx := context.Background()
+	hash := mustHash(t, "password")
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("hash = %q, want PHC form $argon2id$v=19$m=64,t=1,p=1$...", hash)
+	}
+	if parts := strings.Split(hash, "$"); len(parts) != 6 {
+		t.Fatalf("hash has %d $-separated parts, want 6", len(parts))
+	}
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"phc", hash},
+		{"legacy without leading $", strings.TrimPrefix(hash, "$")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if name, ok := password.Identify(tc.hash); !ok || name != "argon2id" {
+				t.Errorf("Identify = (%q, %v), want (argon2id, true)", name, ok)
+			}
+			if err := password.CheckPassword(ctx, tc.hash, "password"); err != nil {
+				t.Errorf("CheckPassword(correct) = %v, want nil", err)
+			}
+			if err := password.CheckPassword(ctx, tc.hash, "wrong"); !errors.Is(err, password.ErrMismatch) {
+				t.Errorf("CheckPassword(wrong) = %v, want ErrMismatch", err)
+			}
+			if password.NeedsRehash(tc.hash) {
+				t.Error("NeedsRehash = true, want false for current params")
+			}
+		})
+	}
+}
+
+func TestHashPasswordUsesFreshSalt(t *testing.T) {
+	useParams(t, testParams)
+	if mustHash(t, "password") == mustHash(t, "password") {
+		t.Error("two hashes of the same password are equal, want different salts")
+	}
+}
@@ -0,0 +1,108 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// minPepperLen คือความยาวขั้นต่ำ (ไบต์) ของ pepper
+const minPepperLen = 16
+
+// maxPepperIDLen คือความยาวสูงสุดของ Pepper.ID ที่ฝังใน hash
+const maxPepperIDLen = 16
+
+// ErrUnknownPepper คือ hash อ้าง pepper (keyid) ที่ไม่ได้ตั้งไว้ ตรวจรหัสไม่ได้จนกว่าจะตั้ง pepper ตัวนั้นกลับมา
+var ErrUnknownPepper = errors.New("ไม่พบ pepper ของ hash นี้")
+
+// Pepper คือ secret ที่ผสมกับรหัสผ่านด้วย HMAC-SHA256 ก่อนเข้า Argon2 เก็บนอกฐานข้อมูล
+// ข้อมูลที่หลุดจากฐานอย่างเดียวจึงเอาไปเดารหัสแบบ offline ไม่ได้
+// ID ถูกฝังใน hash (keyid=<ID>) ให้ pepper หลายตัวใช้ร่วมกันได้ระหว่างหมุน key
+type Pepper struct {
+	ID  string
+	Key []byte
+}
+
+// keyring คือ pepper ที่ SetPeppers ตั้งไว้ current เป็น nil ถ้าไม่ใช้ pepper
+type keyring struct {
+	current *Pepper
+	byID    map[string][]byte
+}
+
+var peppers atomic.Pointer[keyring]
+
+// SetPeppers ตั้ง pepper ตัวแรกใช้กับ hash ใหม่ ตัวอื่นใช้ตรวจ hash เดิมจนกว่าจะถูก hash ใหม่ตอนล็อกอิน
+// ส่งรายการว่างเพื่อเลิกใช้ pepper กับ hash ใหม่ ID ต้องเป็นตัวอักษรอังกฤษหรือตัวเลขไม่เกิน 16 ตัวและไม่ซ้ำกัน
+func SetPeppers(list []Pepper) error {
+	ring := &keyring{byID: make(map[string][]byte, len(list))}
+	for i, p := range list {
+		if err := validPepperID(p.ID); err != nil {
+			return err
+		}
+		if len(p.Key) < minPepperLen {
+			return fmt.Errorf("pepper %s ต้องยาวอย่างน้อย %d ไบต์", p.ID, minPepperLen)
+		}
+		if _, ok := ring.byID[p.ID]; ok {
+			return fmt.Errorf("pepper id ซ้ำ: %s", p.ID)
+		}
+		ring.byID[p.ID] = p.Key
+		if i == 0 {
+			ring.current = &Pepper{ID: p.ID, Key: p.Key}
+		}
+	}
+	peppers.Store(ring)
+	return nil
+}
+
+// currentPepper คืน pepper ที่ใช้กับ hash ใหม่ (nil = ไม่ใช้)
+func currentPepper() *Pepper {
+	if ring := peppers.Load(); ring != nil {
+		return ring.current
+	}
+	return nil
+}
+
+// currentPepperID คืน ID ของ pepper ที่ใช้กับ hash ใหม่ ("" = ไม่ใช้)
+func currentPepperID() string {
+	if p := currentPepper(); p != nil {
+		return p.ID
+	}
+	return ""
+}
+
+// pepperKey คืน pepper ตาม ID ที่ฝังใน hash ("" = hash ไม่ใช้ pepper คืน nil)
+func pepperKey(id string) ([]byte, error) {
+	if id == "" {
+		return nil, nil
+	}
+	if ring := peppers.Load(); ring != nil {
+		if key, ok := ring.byID[id]; ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w (keyid=%s)", ErrUnknownPepper, id)
+}
+
+// peppered คืนค่าที่ส่งเข้า Argon2: HMAC-SHA256(pepper, raw) หรือ raw ตรง ๆ ถ้าไม่มี pepper
+func peppered(key []byte, raw string) []byte {
+	if key == nil {
+		return []byte(raw)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(raw))
+	return mac.Sum(nil)
+}
+
+func validPepperID(id string) error {
+	if id == "" || len(id) > maxPepperIDLen {
+		return fmt.Errorf("pepper id ต้องยาว 1 ถึง %d ตัวอักษร (ได้ %q)", maxPepperIDLen, id)
+	}
+	for _, c := range id {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return fmt.Errorf("pepper id ใช้ได้เฉพาะตัวอักษรอังกฤษและตัวเลข (ได้ %q)", id)
+		}
+	}
+	return nil
+}
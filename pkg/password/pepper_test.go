@@ -0,0 +1,126 @@
+package password_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"fristGoproject/pkg/password"
+)
+
+// usePeppers ตั้ง pepper ระหว่างเทสต์แล้วเลิกใช้ตอนจบ
+func usePeppers(t *testing.T, list ...password.Pepper) {
+	t.Helper()
+	if err := password.SetPeppers(list); err != nil {
+		t.Fatalf("SetPeppers: %v", err)
+	}
+	t.Cleanup(func() { password.SetPeppers(nil) })
+}
+
+var (
+	pepper1 = password.Pepper{ID: "k1", Key: bytes.Repeat([]byte{1}, 32)}
+	pepper2 = password.Pepper{ID: "k2", Key: bytes.Repeat([]byte{2}, 32)}
+)
+
+func TestPepperRotation(t *testing.T) {
+	useParams(t, testParams)
+	ctx := context.Background()
+	plain := mustHash(t, "password")
+
+	usePeppers(t, pepper1)
+	withK1 := mustHash(t, "password")
+	if !strings.Contains(withK1, ",keyid=k1$") {
+		t.Fatalf("hash = %q, want keyid=k1", withK1)
+	}
+	if err := password.CheckPassword(ctx, withK1, "password"); err != nil {
+		t.Fatalf("CheckPassword(k1) = %v, want nil", err)
+	}
+	if err := password.CheckPassword(ctx, withK1, "wrong"); !errors.Is(err, password.ErrMismatch) {
+		t.Fatalf("CheckPassword(k1, wrong) = %v, want ErrMismatch", err)
+	}
+
+	// k2 เป็นตัวหลัก k1 ยังตรวจได้แต่ต้อง rehash
+	usePeppers(t, pepper2, pepper1)
+	tests := []struct {
+		name        string
+		hash        string
+		needsRehash bool
+	}{
+		{"current key", mustHash(t, "password"), false},
+		{"stale key", withK1, true},
+		{"no pepper", plain, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := password.CheckPassword(ctx, tc.hash, "password"); err != nil {
+				t.Errorf("CheckPassword = %v, want nil", err)
+			}
+			if got := password.NeedsRehash(tc.hash); got != tc.needsRehash {
+				t.Errorf("NeedsRehash = %v, want %v", got, tc.needsRehash)
+			}
+		})
+	}
+
+	// เลิกใช้ k1 แล้ว hash ที่อ้าง k1 ต้องตรวจไม่ได้ ไม่ใช่รหัสผิด
+	usePeppers(t, pepper2)
+	if err := password.CheckPassword(ctx, withK1, "password"); !errors.Is(err, password.ErrUnknownPepper) {
+		t.Errorf("CheckPassword(retired k1) = %v, want ErrUnknownPepper", err)
+	}
+	if _, ok := password.Identify(withK1); ok {
+		t.Error("Identify(retired k1) = true, want false")
+	}
+}
+
+func TestPepperChangesHash(t *testing.T) {
+	useParams(t, testParams)
+	usePeppers(t, pepper1)
+	hash := mustHash(t, "password")
+
+	// id เดิมแต่ secret ต่างกัน ต้องตรวจไม่ผ่าน
+	usePeppers(t, password.Pepper{ID: "k1", Key: bytes.Repeat([]byte{9}, 32)})
+	if err := password.CheckPassword(context.Background(), hash, "password"); !errors.Is(err, password.ErrMismatch) {
+		t.Errorf("CheckPassword with different secret = %v, want ErrMismatch", err)
+	}
+}
+
+func TestSetPeppersRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		list []password.Pepper
+	}{
+		{"empty id", []password.Pepper{{ID: "", Key: pepper1.Key}}},
+		{"id with symbol", []password.Pepper{{ID: "k-1", Key: pepper1.Key}}},
+		{"short key", []password.Pepper{{ID: "k1", Key: []byte("short")}}},
+		{"duplicate id", []password.Pepper{pepper1, {ID: "k1", Key: pepper2.Key}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := password.SetPeppers(tc.list); err == nil {
+				password.SetPeppers(nil)
+				t.Error("SetPeppers = nil, want error")
+			}
+		})
+	}
+}
+
+func TestLoadPeppers(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(pepper1.Key)
+	k2 := base64.RawStdEncoding.EncodeToString(pepper2.Key)
+	t.Setenv("PASSWORD_PEPPER", "k2:"+k2+", k1:"+k1)
+
+	list, err := password.LoadPeppers()
+	if err != nil {
+		t.Fatalf("LoadPeppers: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "k2" || !bytes.Equal(list[0].Key, pepper2.Key) || list[1].ID != "k1" || !bytes.Equal(list[1].Key, pepper1.Key) {
+		t.Errorf("LoadPeppers = %+v, want k2 then k1", list)
+	}
+
+	t.Setenv("PASSWORD_PEPPER", "k1-no-secret")
+	if _, err := password.LoadPeppers(); err == nil {
+		t.Error("LoadPeppers without secret = nil error, want error")
+	}
+}
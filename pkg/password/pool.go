@@ -0,0 +1,142 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrBusy คือ hash ที่รันพร้อมกันเต็มโควตาและคิวเต็มหรือรอนานเกิน MaxWait ผู้เรียกควรตอบ 503 ให้ลองใหม่
+var ErrBusy = errors.New("ระบบ hash รหัสผ่านทำงานเต็มกำลัง")
+
+// Limits คุมจำนวน hash ที่รันพร้อมกัน หนึ่งช่องเท่ากับ Memory ของ CurrentParams ตอน SetLimits
+// hash ที่ใช้หน่วยความจำมากกว่านั้น (Argon2 ที่ m สูงกว่า หรือ scrypt) จองหลายช่องตามสัดส่วน
+type Limits struct {
+	// Concurrency คือจำนวนช่อง = จำนวน hash ตามค่าปัจจุบันที่รันพร้อมกันได้
+	Concurrency int
+	// Queue คือจำนวนคำขอที่รอคิวได้ เกินจากนี้ได้ ErrBusy ทันที
+	Queue int
+	// MaxWait คือเวลาที่รอคิวได้นานที่สุดก่อนได้ ErrBusy
+	MaxWait time.Duration
+}
+
+// DefaultLimits คำนวณ Concurrency จากครึ่งหนึ่งของหน่วยความจำที่ process ใช้ได้ (อ่าน limit ของ cgroup ก่อน)
+// หารด้วย Memory ของ CurrentParams และไม่เกิน GOMAXPROCS คิวยาว 16 เท่าของ Concurrency รอได้ไม่เกิน 3 วินาที
+func DefaultLimits() Limits {
+	n := runtime.GOMAXPROCS(0)
+	if mem := availableMemory(); mem > 0 {
+		perHash := uint64(CurrentParams().Memory) * 1024
+		n = min(n, int(mem/2/perHash))
+	}
+	n = max(n, 1)
+	return Limits{Concurrency: n, Queue: 16 * n, MaxWait: 3 * time.Second}
+}
+
+// SetLimits เปลี่ยนโควตาของ HashPassword และ CheckPassword hash ที่รันอยู่หรือรอคิวเดิมอยู่ไม่ได้รับผล
+func SetLimits(l Limits) {
+	current.Store(newPool(l))
+}
+
+// CurrentLimits คืนโควตาที่ใช้อยู่
+func CurrentLimits() Limits {
+	return current.Load().limits
+}
+
+// pool คือ semaphore แบบถ่วงน้ำหนักของ hash พร้อมคิวที่มีขนาดจำกัด
+type pool struct {
+	limits Limits
+	// slotMemory คือหน่วยความจำ (ไบต์) ต่อหนึ่งช่อง
+	slotMemory uint64
+	sem        *semaphore.Weighted
+	waiting    atomic.Int64
+}
+
+func newPool(l Limits) *pool {
+	l.Concurrency = max(l.Concurrency, 1)
+	l.Queue = max(l.Queue, 0)
+	return &pool{
+		limits:     l,
+		slotMemory: uint64(CurrentParams().Memory) * 1024,
+		sem:        semaphore.NewWeighted(int64(l.Concurrency)),
+	}
+}
+
+// weight คืนจำนวนช่องที่ hash ที่ใช้หน่วยความจำ memory ไบต์ต้องจอง อย่างน้อย 1 และไม่เกิน Concurrency
+// (hash ที่ใหญ่กว่าทั้ง pool จะรันได้ทีละตัวโดยไม่มีตัวอื่นพร้อมกัน ขนาดสูงสุดถูกจำกัดด้วย maxArgon2Memory/maxScryptMemory)
+func (p *pool) weight(memory uint64) int64 {
+	n := (memory + p.slotMemory - 1) / p.slotMemory
+	return int64(min(max(n, 1), uint64(p.limits.Concurrency)))
+}
+
+var current atomic.Pointer[pool]
+
+func init() {
+	SetLimits(DefaultLimits())
+}
+
+// acquire จองช่องสำหรับ hash ที่ใช้หน่วยความจำ memory ไบต์ คืนฟังก์ชันคืนช่อง
+// ถ้าเต็มจะรอคิวตามลำดับจน ctx ถูกยกเลิก (คืน ctx.Err()) หรือเกิน MaxWait/คิวเต็ม (คืน ErrBusy)
+func acquire(ctx context.Context, memory uint64) (func(), error) {
+	p := current.Load()
+	w := p.weight(memory)
+	if p.sem.TryAcquire(w) {
+		return p.acquired(w, 0), nil
+	}
+
+	if p.waiting.Add(1) > int64(p.limits.Queue) {
+		p.waiting.Add(-1)
+		metricRejected.Add(1)
+		return nil, ErrBusy
+	}
+	defer p.waiting.Add(-1)
+
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, p.limits.MaxWait)
+	defer cancel()
+	if err := p.sem.Acquire(waitCtx, w); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		metricRejected.Add(1)
+		return nil, ErrBusy
+	}
+	return p.acquired(w, time.Since(start)), nil
+}
+
+func (p *pool) acquired(w int64, wait time.Duration) func() {
+	metricAcquired.Add(1)
+	metricWaitSeconds.Add(wait.Seconds())
+	metricInFlight.Add(1)
+	metricSlotsInUse.Add(w)
+	return func() {
+		metricSlotsInUse.Add(-w)
+		metricInFlight.Add(-1)
+		p.sem.Release(w)
+	}
+}
+
+// metric ของ pool เผยแพร่ผ่าน expvar ใต้ชื่อ password_hash
+// เวลารอคิวเฉลี่ย = wait_seconds_total / acquired_total
+var (
+	metricAcquired    = new(expvar.Int)
+	metricRejected    = new(expvar.Int)
+	metricInFlight    = new(expvar.Int)
+	metricSlotsInUse  = new(expvar.Int)
+	metricWaitSeconds = new(expvar.Float)
+)
+
+func init() {
+	m := expvar.NewMap("password_hash")
+	m.Set("acquired_total", metricAcquired)
+	m.Set("rejected_total", metricRejected)
+	m.Set("in_flight", metricInFlight)
+	m.Set("slots_in_use", metricSlotsInUse)
+	m.Set("wait_seconds_total", metricWaitSeconds)
+	m.Set("queued", expvar.Func(func() any { return current.Load().waiting.Load() }))
+	m.Set("concurrency", expvar.Func(func() any { return current.Load().limits.Concurrency }))
+}